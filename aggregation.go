@@ -0,0 +1,237 @@
+package ucodesdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+/*
+Pipeline builds Mongo-style aggregation pipeline for GetListAggregation:
+
+	pipeline := NewPipeline().
+		Match(map[string]interface{}{"status": "paid"}).
+		Group("$client_id", GroupSum("total", "$amount"), GroupSum("orders", 1)).
+		Sort(SortDesc("total")).
+		Limit(10)
+
+	rows, err := Aggregate[ClientTotal](o, "orders", pipeline)
+
+Stages are sent in Request.Data["pipelines"] in the order they were added.
+*/
+type Pipeline struct {
+	stages []Document
+}
+
+// Document is JSON object which keeps order of its keys, e.g. for $sort stage
+type Document []DocumentField
+
+type DocumentField struct {
+	Key   string
+	Value interface{}
+}
+
+func (d Document) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+
+	for i, field := range d {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		key, err := json.Marshal(field.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := json.Marshal(field.Value)
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling %s: %v", field.Key, err)
+		}
+
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Accumulator is output field of $group stage
+type Accumulator struct {
+	Field    string
+	Operator string
+	Expr     interface{}
+}
+
+// GroupSum adds $sum accumulator of Group, GroupSum("count", 1) counts documents
+func GroupSum(field string, expr interface{}) Accumulator {
+	return Accumulator{Field: field, Operator: "$sum", Expr: expr}
+}
+
+func GroupAvg(field string, expr interface{}) Accumulator {
+	return Accumulator{Field: field, Operator: "$avg", Expr: expr}
+}
+
+func GroupMin(field string, expr interface{}) Accumulator {
+	return Accumulator{Field: field, Operator: "$min", Expr: expr}
+}
+
+func GroupMax(field string, expr interface{}) Accumulator {
+	return Accumulator{Field: field, Operator: "$max", Expr: expr}
+}
+
+func GroupFirst(field string, expr interface{}) Accumulator {
+	return Accumulator{Field: field, Operator: "$first", Expr: expr}
+}
+
+func GroupPush(field string, expr interface{}) Accumulator {
+	return Accumulator{Field: field, Operator: "$push", Expr: expr}
+}
+
+// SortKey is field of $sort stage, see SortAsc and SortDesc
+type SortKey struct {
+	Field string
+	Order int
+}
+
+func SortAsc(field string) SortKey {
+	return SortKey{Field: field, Order: 1}
+}
+
+func SortDesc(field string) SortKey {
+	return SortKey{Field: field, Order: -1}
+}
+
+func NewPipeline() *Pipeline {
+	return &Pipeline{}
+}
+
+// Stage adds custom stage, e.g. Stage("$count", "total")
+func (p *Pipeline) Stage(operator string, value interface{}) *Pipeline {
+	p.stages = append(p.stages, Document{{Key: operator, Value: value}})
+	return p
+}
+
+func (p *Pipeline) Match(filter map[string]interface{}) *Pipeline {
+	return p.Stage("$match", filter)
+}
+
+// Group groups documents by id, e.g. "$client_id" or map of fields for compound key, nil groups all documents
+func (p *Pipeline) Group(id interface{}, accumulators ...Accumulator) *Pipeline {
+	var group = Document{{Key: "_id", Value: id}}
+	for _, acc := range accumulators {
+		group = append(group, DocumentField{Key: acc.Field, Value: map[string]interface{}{acc.Operator: acc.Expr}})
+	}
+
+	return p.Stage("$group", group)
+}
+
+// Project keeps, computes or excludes fields, ProjectQuery result can be passed as is
+func (p *Pipeline) Project(fields map[string]interface{}) *Pipeline {
+	return p.Stage("$project", fields)
+}
+
+func (p *Pipeline) Sort(keys ...SortKey) *Pipeline {
+	var sort = make(Document, 0, len(keys))
+	for _, key := range keys {
+		sort = append(sort, DocumentField{Key: key.Field, Value: key.Order})
+	}
+
+	return p.Stage("$sort", sort)
+}
+
+func (p *Pipeline) Limit(limit int) *Pipeline {
+	return p.Stage("$limit", limit)
+}
+
+func (p *Pipeline) Skip(skip int) *Pipeline {
+	return p.Stage("$skip", skip)
+}
+
+// Lookup joins documents of table from whose foreignField equals localField into array field as
+func (p *Pipeline) Lookup(from, localField, foreignField, as string) *Pipeline {
+	return p.Stage("$lookup", Document{
+		{Key: "from", Value: from},
+		{Key: "localField", Value: localField},
+		{Key: "foreignField", Value: foreignField},
+		{Key: "as", Value: as},
+	})
+}
+
+// Unwind outputs document per element of array field path, preserveEmpty keeps documents without elements
+func (p *Pipeline) Unwind(path string, preserveEmpty bool) *Pipeline {
+	if !strings.HasPrefix(path, "$") {
+		path = "$" + path
+	}
+
+	if !preserveEmpty {
+		return p.Stage("$unwind", path)
+	}
+
+	return p.Stage("$unwind", Document{
+		{Key: "path", Value: path},
+		{Key: "preserveNullAndEmptyArrays", Value: true},
+	})
+}
+
+// Stages returns stages added so far
+func (p *Pipeline) Stages() []Document {
+	return p.stages
+}
+
+func (p *Pipeline) has(operator string) bool {
+	for _, stage := range p.stages {
+		if len(stage) > 0 && stage[0].Key == operator {
+			return true
+		}
+	}
+	return false
+}
+
+// Request returns request body of GetListAggregation
+func (p *Pipeline) Request() Request {
+	var stages = p.stages
+	if stages == nil {
+		stages = []Document{}
+	}
+
+	return Request{Data: map[string]interface{}{"pipelines": stages}}
+}
+
+/*
+Aggregate runs pipeline on table with GetListAggregation and decodes rows into T,
+which is usually a struct with json tags matching fields produced by the pipeline.
+*/
+func Aggregate[T any](o *ObjectFunction, tableSlug string, pipeline *Pipeline, opts ...CallOption) ([]T, error) {
+	arg, call := o.prepare(&Argument{TableSlug: tableSlug, Request: pipeline.Request()}, opts)
+
+	var url = fmt.Sprintf("%s/v2/items/%s/aggregation", o.Cfg.BaseURL, arg.TableSlug)
+
+	// cached result is invalidated by writes to tableSlug only, joined tables would go stale
+	if pipeline.has("$lookup") {
+		call.skipCache = true
+	}
+
+	respByte, err := o.doRequest(call, apiRequest{method: "POST", url: url, body: arg.Request, kind: requestAggregate, tables: []string{arg.TableSlug}})
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data struct {
+			Data struct {
+				Data []T `json:"data"`
+			} `json:"data"`
+		} `json:"data"`
+	}
+
+	if err = o.unmarshal(respByte, &result); err != nil {
+		return nil, fmt.Errorf("error unmarshalling aggregation rows: %v", err)
+	}
+
+	return result.Data.Data.Data, nil
+}
@@ -0,0 +1,226 @@
+package ucodesdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// benchmarkServer answers u-code API requests with canned responses of benchmarkRows rows
+func benchmarkServer(b *testing.B) *httptest.Server {
+	const benchmarkRows = 50
+
+	var rows = make([]map[string]interface{}, benchmarkRows)
+	for i := range rows {
+		rows[i] = map[string]interface{}{
+			"guid":        fmt.Sprintf("guid-%d", i),
+			"name":        fmt.Sprintf("object %d", i),
+			"price":       float64(i) * 1.5,
+			"active":      i%2 == 0,
+			"order_id":    "guid-0",
+			"product_ids": []string{"guid-1", "guid-2"},
+		}
+	}
+
+	var (
+		object        = rows[0]
+		list, _       = json.Marshal(map[string]interface{}{"data": map[string]interface{}{"data": map[string]interface{}{"count": len(rows), "response": rows}}})
+		single, _     = json.Marshal(map[string]interface{}{"data": map[string]interface{}{"data": map[string]interface{}{"response": object}}})
+		created, _    = json.Marshal(map[string]interface{}{"data": map[string]interface{}{"data": map[string]interface{}{"data": object}}})
+		updated, _    = json.Marshal(map[string]interface{}{"status": "done", "data": map[string]interface{}{"table_slug": "order", "data": object}})
+		multiple, _   = json.Marshal(map[string]interface{}{"status": "done", "data": map[string]interface{}{"data": map[string]interface{}{"objects": rows}}})
+		upserted, _   = json.Marshal(map[string]interface{}{"status": "done", "data": map[string]interface{}{"data": object}})
+		aggregated, _ = json.Marshal(map[string]interface{}{"data": map[string]interface{}{"data": map[string]interface{}{"data": rows}}})
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var path = r.URL.Path
+		switch {
+		case strings.Contains(path, "/get-list"):
+			w.Write(list)
+		case strings.HasSuffix(path, "/aggregation"):
+			w.Write(aggregated)
+		case strings.Contains(path, "/multiple-update/"):
+			w.Write(multiple)
+		case strings.HasSuffix(path, "/upsert-many"):
+			w.Write(upserted)
+		case strings.HasSuffix(path, "/many-to-many"), r.Method == http.MethodDelete:
+			w.Write([]byte(`{}`))
+		case r.Method == http.MethodGet:
+			w.Write(single)
+		case r.Method == http.MethodPost:
+			w.Write(created)
+		default:
+			w.Write(updated)
+		}
+	}))
+	b.Cleanup(server.Close)
+
+	return server
+}
+
+func benchmarkArgument(data map[string]interface{}) *Argument {
+	return &Argument{TableSlug: "order", Request: Request{Data: data}}
+}
+
+func BenchmarkObjectFunction(b *testing.B) {
+	var (
+		o        = New(&Config{BaseURL: benchmarkServer(b).URL, AppId: "app"})
+		ctx      = context.Background()
+		relation = Relation{TableFrom: "order", IdFrom: "guid-0", TableTo: "product", IdTo: []string{"guid-2", "guid-3"}}
+		pipeline = NewPipeline().Match(map[string]interface{}{"active": true}).Group("$name", GroupSum("total", "$price"))
+		ignore   = func(row map[string]interface{}) error { return nil }
+	)
+
+	var methods = []struct {
+		name string
+		call func() error
+	}{
+		{"CreateObject", func() error {
+			_, _, err := o.CreateObject(benchmarkArgument(map[string]interface{}{"name": "object", "price": 10}))
+			return err
+		}},
+		{"UpdateObject", func() error {
+			_, _, err := o.UpdateObject(benchmarkArgument(map[string]interface{}{"guid": "guid-0", "price": 10}))
+			return err
+		}},
+		{"MultipleUpdate", func() error {
+			_, _, err := o.MultipleUpdate(benchmarkArgument(map[string]interface{}{"objects": []map[string]interface{}{{"guid": "guid-0"}}}))
+			return err
+		}},
+		{"GetList", func() error {
+			_, _, err := o.GetList(benchmarkArgument(map[string]interface{}{"limit": 50}))
+			return err
+		}},
+		{"GetListSlim", func() error {
+			_, _, err := o.GetListSlim(benchmarkArgument(map[string]interface{}{"limit": 50, "page": 1, "name": "object & co"}))
+			return err
+		}},
+		{"GetListAggregate", func() error {
+			_, _, err := o.GetListAggregate(benchmarkArgument(map[string]interface{}{}))
+			return err
+		}},
+		{"GetSingle", func() error {
+			_, _, err := o.GetSingle(benchmarkArgument(map[string]interface{}{"guid": "guid-0"}))
+			return err
+		}},
+		{"GetSingleSlim", func() error {
+			_, _, err := o.GetSingleSlim(benchmarkArgument(map[string]interface{}{"guid": "guid-0"}))
+			return err
+		}},
+		{"GetListAggregation", func() error {
+			_, _, err := o.GetListAggregation(&Argument{TableSlug: "order", Request: pipeline.Request()})
+			return err
+		}},
+		{"AppendManyToMany", func() error {
+			_, err := o.AppendManyToMany(benchmarkArgument(relation.data(relation.IdTo)))
+			return err
+		}},
+		{"DeleteManyToMany", func() error {
+			_, err := o.DeleteManyToMany(benchmarkArgument(relation.data(relation.IdTo)))
+			return err
+		}},
+		{"Delete", func() error {
+			_, err := o.Delete(benchmarkArgument(map[string]interface{}{"guid": "guid-0"}))
+			return err
+		}},
+		{"MultipleDelete", func() error {
+			_, err := o.MultipleDelete(benchmarkArgument(map[string]interface{}{"ids": []string{"guid-0", "guid-1"}}))
+			return err
+		}},
+		{"MultipleUpsert", func() error {
+			var arg = benchmarkArgument(nil)
+			arg.UpsertRequest.Data.Objects = []map[string]interface{}{{"guid": "guid-0", "price": 10}}
+			_, _, err := o.MultipleUpsert(arg)
+			return err
+		}},
+		{"GetListStream", func() error {
+			_, err := o.GetListStream(benchmarkArgument(map[string]interface{}{"limit": 50}), ignore)
+			return err
+		}},
+		{"GetListSlimStream", func() error {
+			_, err := o.GetListSlimStream(benchmarkArgument(map[string]interface{}{"limit": 50}), ignore)
+			return err
+		}},
+		{"StreamList", func() error {
+			var stream = o.StreamList(benchmarkArgument(map[string]interface{}{"limit": 50}))
+			for range stream.Rows() {
+			}
+			_, err := stream.Wait()
+			return err
+		}},
+		{"Aggregate", func() error {
+			_, err := Aggregate[map[string]interface{}](o, "order", pipeline)
+			return err
+		}},
+		{"GetSingleAs", func() error {
+			_, err := GetSingleAs[map[string]interface{}](o, benchmarkArgument(map[string]interface{}{"guid": "guid-0"}))
+			return err
+		}},
+		{"GetListAs", func() error {
+			_, err := GetListAs[map[string]interface{}](o, benchmarkArgument(map[string]interface{}{"limit": 50}))
+			return err
+		}},
+		{"CreateObjectAs", func() error {
+			_, err := CreateObjectAs[map[string]interface{}](o, benchmarkArgument(map[string]interface{}{"name": "object"}))
+			return err
+		}},
+		{"UpdateObjectAs", func() error {
+			_, err := UpdateObjectAs[map[string]interface{}](o, benchmarkArgument(map[string]interface{}{"guid": "guid-0", "price": 10}))
+			return err
+		}},
+		{"GetListExpand", func() error {
+			_, _, err := o.GetList(benchmarkArgument(map[string]interface{}{"limit": 50}), WithCallExpand("product_ids"))
+			return err
+		}},
+		{"LinkMany", func() error {
+			_, err := o.LinkMany(ctx, relation)
+			return err
+		}},
+		{"UnlinkMany", func() error {
+			_, err := o.UnlinkMany(ctx, relation)
+			return err
+		}},
+		{"ReplaceMany", func() error {
+			_, _, err := o.ReplaceMany(ctx, relation)
+			return err
+		}},
+		{"ListRelated", func() error {
+			_, _, err := o.ListRelated(ctx, relation)
+			return err
+		}},
+		{"Patch", func() error {
+			_, _, err := o.Patch(benchmarkArgument(nil), map[string]interface{}{"guid": "guid-0", "price": 1}, map[string]interface{}{"guid": "guid-0", "price": 2})
+			return err
+		}},
+	}
+
+	for _, method := range methods {
+		b.Run(method.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := method.call(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkGetListSlimRequest(b *testing.B) {
+	var (
+		o    = New(&Config{BaseURL: "https://api.example.com", AppId: "app"})
+		data = map[string]interface{}{"limit": 50, "page": 2, "name": "object & co", "ids": []string{"guid-1", "guid-2"}}
+	)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := o.getListSlimRequest(benchmarkArgument(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
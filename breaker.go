@@ -0,0 +1,327 @@
+package ucodesdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is matched by errors.Is for calls rejected by open circuit breaker
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// BreakerState is state of circuit breaker
+type BreakerState int
+
+const (
+	// BreakerClosed lets every request through
+	BreakerClosed BreakerState = iota
+	// BreakerOpen fails requests fast without sending them
+	BreakerOpen
+	// BreakerHalfOpen lets a few probe requests through to check if the backend recovered
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// BreakerScope decides which requests share one circuit breaker
+type BreakerScope int
+
+const (
+	// BreakerPerHost shares breaker between every request to the same host
+	BreakerPerHost BreakerScope = iota
+	// BreakerPerEndpoint keeps breaker per method and path, table slugs and ids are not part of the path
+	BreakerPerEndpoint
+)
+
+/*
+BreakerConfig configures circuit breaker of u-code API requests.
+Network errors, 429 and 5xx responses are failures, other responses are successes.
+Breaker opens after ConsecutiveFailures failures in a row or when share of failures
+within Window reaches FailureRate (after at least MinRequests requests).
+After OpenTimeout it lets HalfOpenRequests probes through, it closes when all of them succeed.
+*/
+type BreakerConfig struct {
+	Scope               BreakerScope
+	ConsecutiveFailures int
+	FailureRate         float64
+	MinRequests         int
+	Window              time.Duration
+	OpenTimeout         time.Duration
+	HalfOpenRequests    int
+
+	// OnStateChange is called on every state change with the scope the breaker guards
+	OnStateChange func(scope string, from, to BreakerState)
+	// AlertTelegram sends state changes to Telegram with SendTelegramAsync
+	AlertTelegram bool
+}
+
+// CircuitOpenError is returned for requests rejected by open circuit breaker
+type CircuitOpenError struct {
+	Scope      string
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker of %s is open, retry after %s", e.Scope, e.RetryAfter.Round(time.Millisecond))
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// WithCircuitBreaker guards u-code API requests with circuit breaker
+func WithCircuitBreaker(cfg BreakerConfig) Option {
+	return func(o *ObjectFunction) {
+		if cfg.MinRequests <= 0 {
+			cfg.MinRequests = 10
+		}
+		if cfg.Window <= 0 {
+			cfg.Window = time.Minute
+		}
+		if cfg.OpenTimeout <= 0 {
+			cfg.OpenTimeout = 30 * time.Second
+		}
+		if cfg.HalfOpenRequests <= 0 {
+			cfg.HalfOpenRequests = 1
+		}
+		o.breakerCfg = &cfg
+	}
+}
+
+// BreakerStates returns state of every circuit breaker by its scope
+func (o *ObjectFunction) BreakerStates() map[string]BreakerState {
+	o.breakersMu.Lock()
+	defer o.breakersMu.Unlock()
+
+	var states = make(map[string]BreakerState, len(o.breakers))
+	for scope, b := range o.breakers {
+		b.mu.Lock()
+		states[scope] = b.state
+		b.mu.Unlock()
+	}
+
+	return states
+}
+
+// breaker returns circuit breaker guarding req, nil when breaker is not configured
+func (o *ObjectFunction) breaker(req apiRequest) *circuitBreaker {
+	if o.breakerCfg == nil {
+		return nil
+	}
+
+	var scope = breakerScope(o.breakerCfg.Scope, req)
+
+	o.breakersMu.Lock()
+	defer o.breakersMu.Unlock()
+
+	if o.breakers == nil {
+		o.breakers = map[string]*circuitBreaker{}
+	}
+
+	b, ok := o.breakers[scope]
+	if !ok {
+		b = &circuitBreaker{scope: scope, cfg: o.breakerCfg, notify: o.breakerStateChanged}
+		o.breakers[scope] = b
+	}
+
+	return b
+}
+
+func (o *ObjectFunction) breakerStateChanged(scope string, from, to BreakerState) {
+	if o.breakerCfg.OnStateChange != nil {
+		o.breakerCfg.OnStateChange(scope, from, to)
+	}
+
+	if o.breakerCfg.AlertTelegram {
+		_ = o.SendTelegramAsync(fmt.Sprintf("u-code circuit breaker of %s: %s -> %s", scope, from, to))
+	}
+}
+
+func breakerScope(scope BreakerScope, req apiRequest) string {
+	parsed, err := url.Parse(req.url)
+	if err != nil {
+		return req.url
+	}
+
+	if scope == BreakerPerHost {
+		return parsed.Host
+	}
+
+	var segments = strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	for i, segment := range segments {
+		switch {
+		case segment != "" && Contains(req.tables, segment):
+			segments[i] = "{table}"
+		case looksLikeId(segment):
+			segments[i] = "{id}"
+		}
+	}
+
+	return req.method + " " + parsed.Host + "/" + strings.Join(segments, "/")
+}
+
+// looksLikeId reports whether path segment is guid or numeric id
+func looksLikeId(segment string) bool {
+	var digits int
+	for _, r := range segment {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '-' || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F'):
+		default:
+			return false
+		}
+	}
+
+	return digits > 0 && (digits == len(segment) || len(segment) >= 16)
+}
+
+type circuitBreaker struct {
+	scope  string
+	cfg    *BreakerConfig
+	notify func(scope string, from, to BreakerState)
+
+	mu          sync.Mutex
+	state       BreakerState
+	consecutive int
+	requests    int
+	failures    int
+	windowStart time.Time
+	openedAt    time.Time
+	probes      int
+	successes   int
+}
+
+type breakerOutcome int
+
+const (
+	breakerSuccess breakerOutcome = iota
+	breakerFailure
+	// breakerIgnored is outcome of requests cancelled by the caller
+	breakerIgnored
+)
+
+// allow reports whether request may be sent, it has to be followed by record when it may
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+
+	var from = b.state
+	if b.state == BreakerOpen {
+		if wait := b.cfg.OpenTimeout - time.Since(b.openedAt); wait > 0 {
+			b.mu.Unlock()
+			return &CircuitOpenError{Scope: b.scope, RetryAfter: wait}
+		}
+		b.state, b.probes, b.successes = BreakerHalfOpen, 0, 0
+	}
+
+	if b.state == BreakerHalfOpen {
+		if b.probes >= b.cfg.HalfOpenRequests {
+			b.mu.Unlock()
+			return &CircuitOpenError{Scope: b.scope}
+		}
+		b.probes++
+	}
+
+	var to = b.state
+	b.mu.Unlock()
+
+	if from != to {
+		b.notify(b.scope, from, to)
+	}
+
+	return nil
+}
+
+func (b *circuitBreaker) record(outcome breakerOutcome) {
+	b.mu.Lock()
+
+	var (
+		from = b.state
+		now  = time.Now()
+	)
+
+	switch b.state {
+	case BreakerHalfOpen:
+		// request allowed before the breaker opened may finish while it is half-open
+		if b.probes > 0 {
+			b.probes--
+		}
+		switch outcome {
+		case breakerFailure:
+			b.open(now)
+		case breakerSuccess:
+			b.successes++
+			if b.successes >= b.cfg.HalfOpenRequests {
+				b.close(now)
+			}
+		}
+
+	case BreakerClosed:
+		if outcome == breakerIgnored {
+			break
+		}
+
+		if now.Sub(b.windowStart) > b.cfg.Window {
+			b.windowStart, b.requests, b.failures = now, 0, 0
+		}
+
+		b.requests++
+		if outcome == breakerSuccess {
+			b.consecutive = 0
+			break
+		}
+
+		b.failures++
+		b.consecutive++
+
+		var (
+			byConsecutive = b.cfg.ConsecutiveFailures > 0 && b.consecutive >= b.cfg.ConsecutiveFailures
+			byRate        = b.cfg.FailureRate > 0 && b.requests >= b.cfg.MinRequests && float64(b.failures)/float64(b.requests) >= b.cfg.FailureRate
+		)
+
+		if byConsecutive || byRate {
+			b.open(now)
+		}
+	}
+
+	var to = b.state
+	b.mu.Unlock()
+
+	if from != to {
+		b.notify(b.scope, from, to)
+	}
+}
+
+func (b *circuitBreaker) open(now time.Time) {
+	b.state, b.openedAt = BreakerOpen, now
+}
+
+func (b *circuitBreaker) close(now time.Time) {
+	b.state, b.consecutive, b.requests, b.failures, b.windowStart = BreakerClosed, 0, 0, 0, now
+}
+
+func breakerOutcomeOf(err error) breakerOutcome {
+	switch {
+	case err == nil:
+		return breakerSuccess
+	case errors.Is(err, context.Canceled):
+		return breakerIgnored
+	case isRetryable(err):
+		return breakerFailure
+	default:
+		return breakerSuccess
+	}
+}
@@ -0,0 +1,191 @@
+package ucodesdk
+
+import (
+	"container/list"
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+/*
+CacheBackend stores cached responses of read calls. MemoryCache is the built-in implementation,
+a Redis-like store can be plugged in by implementing the interface. Backends must be safe for
+concurrent use. Keys are hashes, values are raw response bodies.
+*/
+type CacheBackend interface {
+	Get(ctx context.Context, key string) ([]byte, bool)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration)
+	Delete(ctx context.Context, key string)
+}
+
+/*
+WithCache caches responses of GetSingle, GetList and other read calls in backend for ttl.
+Key is built from app id, table slug, endpoint, request body and headers set with WithHeaders
+and WithCallHeaders, so callers sending different headers never share responses. Any write through the same
+client invalidates cached reads of the written table.
+*/
+func WithCache(backend CacheBackend, ttl time.Duration) Option {
+	return func(o *ObjectFunction) {
+		o.cache = backend
+		o.cacheTTL = ttl
+	}
+}
+
+// WithCallSkipCache bypasses client cache for read call, the fresh response is not stored either.
+// Writes invalidate cached tables with or without it.
+func WithCallSkipCache() CallOption {
+	return func(c *callOptions) {
+		c.skipCache = true
+	}
+}
+
+// InvalidateTable drops cached reads of table for app id (Config.AppId when empty)
+func (o *ObjectFunction) InvalidateTable(appId, tableSlug string) error {
+	if o.cache == nil {
+		return nil
+	}
+
+	appId, err := o.appId(context.Background(), firstNonEmpty(appId, o.Cfg.AppId))
+	if err != nil {
+		return err
+	}
+
+	o.invalidateTable(context.Background(), appId, tableSlug)
+	return nil
+}
+
+// cacheKey includes generation of the table, invalidation moves table to a new generation
+// so old entries are never read again and expire by ttl or eviction
+func (o *ObjectFunction) cacheKey(ctx context.Context, appId string, req apiRequest, body []byte, headers string) string {
+	var (
+		table      = req.tables[0]
+		generation = "0"
+	)
+
+	if value, ok := o.cache.Get(ctx, o.generationKey(appId, table)); ok {
+		generation = string(value)
+	}
+
+	return "ucode:" + HashSHA256(appId+"\n"+table+"\n"+generation+"\n"+req.method+" "+req.url+"\n"+string(body)+"\n"+headers)
+}
+
+func (o *ObjectFunction) generationKey(appId, tableSlug string) string {
+	return "ucode:gen:" + HashSHA256(appId+"\n"+tableSlug)
+}
+
+func (o *ObjectFunction) invalidateTable(ctx context.Context, appId, tableSlug string) {
+	// generation has to outlive every entry written with the previous one
+	var generation = strconv.FormatInt(time.Now().UnixNano(), 36)
+	o.cache.Set(ctx, o.generationKey(appId, tableSlug), []byte(generation), 0)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// MemoryCache is in-process LRU CacheBackend bounded by number of entries.
+// It survives between warm invocations when kept in a package level variable.
+type MemoryCache struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type memoryCacheEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCache creates LRU cache, least recently used entries are evicted above maxEntries
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = 1000
+	}
+
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		entries:    map[string]*list.Element{},
+		order:      list.New(),
+	}
+}
+
+func (c *MemoryCache) Get(ctx context.Context, key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*memoryCacheEntry)
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		c.remove(element)
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	return entry.value, true
+}
+
+// Set stores value, ttl <= 0 keeps it until eviction
+func (c *MemoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expires time.Time
+	if ttl > 0 {
+		expires = time.Now().Add(ttl)
+	}
+
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*memoryCacheEntry)
+		entry.value, entry.expires = value, expires
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&memoryCacheEntry{key: key, value: value, expires: expires})
+
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *MemoryCache) Delete(ctx context.Context, key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+}
+
+// Len returns number of stored entries including expired ones not yet evicted
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+func (c *MemoryCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*memoryCacheEntry).key)
+}
+
+// manyToManyTables lists tables changed by many-to-many request, both sides store the relation
+func manyToManyTables(arg *Argument) []string {
+	return []string{cast.ToString(arg.Request.Data["table_from"]), cast.ToString(arg.Request.Data["table_to"])}
+}
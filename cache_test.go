@@ -0,0 +1,56 @@
+package ucodesdk
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacheKeyHeaders(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		fmt.Fprintf(w, `{"data": {"data": {"response": {"guid": "guid-1", "user": %q}}}}`, r.Header.Get("X-User"))
+	}))
+	defer server.Close()
+
+	var (
+		o   = New(&Config{BaseURL: server.URL, AppId: "app"}, WithCache(NewMemoryCache(100), time.Minute), WithHeaders(map[string]string{"X-Tenant": "shop"}))
+		arg = &Argument{TableSlug: "order", Request: Request{Data: map[string]interface{}{"guid": "guid-1"}}}
+	)
+
+	for _, test := range []struct {
+		user     string
+		requests int32
+	}{
+		{"alice", 1},
+		{"bob", 2},
+		{"alice", 2},
+		{"bob", 2},
+	} {
+		object, _, err := o.GetSingle(arg, WithCallHeaders(map[string]string{"x-user": test.user}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if user := object.Data.Data.Response["user"]; user != test.user {
+			t.Errorf("%s got response of %v", test.user, user)
+		}
+		if count := requests.Load(); count != test.requests {
+			t.Errorf("%s: %d requests sent, want %d", test.user, count, test.requests)
+		}
+	}
+
+	// client headers are part of the key too, clients may share one backend
+	var other = New(&Config{BaseURL: server.URL, AppId: "app"}, WithCache(o.cache, time.Minute), WithHeaders(map[string]string{"X-Tenant": "market"}))
+	if _, _, err := other.GetSingle(arg, WithCallHeaders(map[string]string{"X-User": "alice"})); err != nil {
+		t.Fatal(err)
+	}
+	if count := requests.Load(); count != 3 {
+		t.Errorf("client with other headers is served from cache, %d requests sent", count)
+	}
+}
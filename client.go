@@ -0,0 +1,491 @@
+package ucodesdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Option configures ObjectFunction in New
+type Option func(o *ObjectFunction)
+
+// WithHTTPClient sets client used for u-code API requests, http.DefaultClient settings are used by default
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *ObjectFunction) {
+		o.httpClient = client
+	}
+}
+
+// WithLogger replaces logger created from Config.FunctionName
+func WithLogger(logger *FaasLogger) Option {
+	return func(o *ObjectFunction) {
+		o.Logger = logger
+	}
+}
+
+/*
+WithRetry repeats failed read requests (network errors, 429 and 5xx responses) up to attempts
+more times, waiting backoff before the first retry and doubling it after every attempt.
+Create, update and delete calls are not retried since they are not idempotent,
+unless they are made with WithCallIdempotencyKey.
+*/
+func WithRetry(attempts int, backoff time.Duration) Option {
+	return func(o *ObjectFunction) {
+		o.retryAttempts = attempts
+		o.retryBackoff = backoff
+	}
+}
+
+// WithTimeout limits every u-code API request, including reading the response body
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *ObjectFunction) {
+		o.timeout = timeout
+	}
+}
+
+// WithUserAgent sets User-Agent header of u-code API requests
+func WithUserAgent(userAgent string) Option {
+	return func(o *ObjectFunction) {
+		o.userAgent = userAgent
+	}
+}
+
+// WithHeaders adds headers to every u-code API request
+func WithHeaders(headers map[string]string) Option {
+	return func(o *ObjectFunction) {
+		if o.headers == nil {
+			o.headers = map[string]string{}
+		}
+		for key, value := range headers {
+			o.headers[key] = value
+		}
+	}
+}
+
+// CallOption overrides settings for a single method call
+type CallOption func(c *callOptions)
+
+type callOptions struct {
+	ctx         context.Context
+	appId       string
+	timeout     time.Duration
+	isCached    *bool
+	blockCached *bool
+	headers     map[string]string
+	skipCache   bool
+	noCoalesce  bool
+
+	idempotencyKey string
+	requestId      string
+
+	expand      []Expansion
+	expandDepth int
+
+	verifyStored bool
+	verifyFields []string
+
+	// filled while the call runs, see ObjectFunction.result
+	started         time.Time
+	attempts        int
+	statusCode      int
+	serverRequestId string
+}
+
+// WithCallContext sets context of the call, request is cancelled with it
+func WithCallContext(ctx context.Context) CallOption {
+	return func(c *callOptions) {
+		c.ctx = ctx
+	}
+}
+
+// WithCallAppId overrides Argument.AppId and Config.AppId
+func WithCallAppId(appId string) CallOption {
+	return func(c *callOptions) {
+		c.appId = appId
+	}
+}
+
+// WithCallTimeout overrides WithTimeout for the call
+func WithCallTimeout(timeout time.Duration) CallOption {
+	return func(c *callOptions) {
+		c.timeout = timeout
+	}
+}
+
+// WithCallCache overrides Argument.Request.IsCached
+func WithCallCache(isCached bool) CallOption {
+	return func(c *callOptions) {
+		c.isCached = &isCached
+	}
+}
+
+// WithCallBlockCached overrides Argument.BlockCached
+func WithCallBlockCached(blockCached bool) CallOption {
+	return func(c *callOptions) {
+		c.blockCached = &blockCached
+	}
+}
+
+// WithCallHeaders adds headers to the call, they override WithHeaders
+func WithCallHeaders(headers map[string]string) CallOption {
+	return func(c *callOptions) {
+		if c.headers == nil {
+			c.headers = map[string]string{}
+		}
+		for key, value := range headers {
+			c.headers[key] = value
+		}
+	}
+}
+
+type requestKind int
+
+const (
+	requestRead requestKind = iota
+	requestWrite
+	requestAggregate
+)
+
+func (k requestKind) class() EndpointClass {
+	switch k {
+	case requestWrite:
+		return EndpointWrite
+	case requestAggregate:
+		return EndpointAggregation
+	default:
+		return EndpointRead
+	}
+}
+
+// apiRequest describes one u-code API request.
+// Reads are cached under the first of tables, writes invalidate all of them.
+type apiRequest struct {
+	method string
+	url    string
+	body   interface{}
+	kind   requestKind
+	tables []string
+}
+
+// prepare applies call options to a copy of arg, the caller's Argument is left untouched
+func (o *ObjectFunction) prepare(arg *Argument, opts []CallOption) (*Argument, *callOptions) {
+	var call = &callOptions{ctx: context.Background(), started: time.Now()}
+	for _, opt := range opts {
+		opt(call)
+	}
+
+	if call.requestId == "" {
+		call.requestId = newRequestId()
+	}
+
+	var copied = *arg
+
+	if call.isCached != nil {
+		copied.Request.IsCached = *call.isCached
+	}
+
+	if call.blockCached != nil {
+		copied.BlockCached = *call.blockCached
+	}
+
+	switch {
+	case call.appId != "":
+		copied.AppId = call.appId
+	case copied.AppId == "":
+		copied.AppId = o.Cfg.AppId
+	}
+	call.appId = copied.AppId
+
+	return &copied, call
+}
+
+// doRequest sends request to u-code API with client options applied.
+// Error responses are returned as *ResponseError with the response body as message,
+// secrets are redacted from every returned error.
+func (o *ObjectFunction) doRequest(call *callOptions, req apiRequest) ([]byte, error) {
+	appId, err := o.appId(call.ctx, call.appId)
+	if err != nil {
+		return nil, err
+	}
+	call.appId = appId
+	o.rememberSecret(appId)
+
+	data, err := o.requestData(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.kind != requestWrite {
+		return o.read(call, appId, req, data)
+	}
+
+	if o.cache != nil {
+		// write may be applied even when its response is lost, so tables are invalidated anyway
+		defer func() {
+			for _, table := range req.tables {
+				if table != "" {
+					o.invalidateTable(call.ctx, appId, table)
+				}
+			}
+		}()
+	}
+
+	if call.idempotencyKey != "" {
+		return o.idempotentWrite(call, appId, req, data)
+	}
+
+	return o.sendWithRetry(call, req, data)
+}
+
+// read serves read request from cache, then from identical in-flight request, then from API
+func (o *ObjectFunction) read(call *callOptions, appId string, req apiRequest, data []byte) ([]byte, error) {
+	var (
+		useCache = o.cache != nil && !call.skipCache && len(req.tables) > 0
+		headers  = o.headerKey(call)
+		cacheKey string
+	)
+
+	if useCache {
+		cacheKey = o.cacheKey(call.ctx, appId, req, data, headers)
+		if respByte, ok := o.cache.Get(call.ctx, cacheKey); ok {
+			return respByte, nil
+		}
+	}
+
+	var (
+		respByte []byte
+		err      error
+		fetch    = func() ([]byte, error) { return o.sendWithRetry(call, req, data) }
+	)
+
+	if o.noCoalesce || call.noCoalesce {
+		respByte, err = fetch()
+	} else {
+		// call that joins in-flight request waits for it no longer than for its own request
+		var ctx = call.ctx
+		if timeout := o.callTimeout(call); timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		respByte, err = o.inflight.do(ctx, HashSHA256(appId+"\n"+req.method+" "+req.url+"\n"+string(data)+"\n"+headers), fetch)
+	}
+
+	if err == nil && useCache {
+		o.cache.Set(call.ctx, cacheKey, respByte, o.cacheTTL)
+	}
+
+	return respByte, err
+}
+
+// headerKey returns headers set with WithHeaders and WithCallHeaders as they are sent, for cache and coalescing keys
+func (o *ObjectFunction) headerKey(call *callOptions) string {
+	if len(o.headers) == 0 && len(call.headers) == 0 {
+		return ""
+	}
+
+	var headers = make(map[string]string, len(o.headers)+len(call.headers))
+	for key, value := range o.headers {
+		headers[http.CanonicalHeaderKey(key)] = value
+	}
+	for key, value := range call.headers {
+		headers[http.CanonicalHeaderKey(key)] = value
+	}
+
+	// map keys are marshalled sorted
+	data, _ := json.Marshal(headers)
+	return string(data)
+}
+
+func (o *ObjectFunction) sendWithRetry(call *callOptions, req apiRequest, data []byte) ([]byte, error) {
+	var respByte []byte
+
+	err := o.withRetry(call, req, data, func(body io.Reader, size int64) (err error) {
+		respByte, err = readBody(body, size)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return respByte, nil
+}
+
+// withRetry sends request with limits, circuit breaker and retries applied.
+// read consumes body of successful response, its error is retried as the request error.
+func (o *ObjectFunction) withRetry(call *callOptions, req apiRequest, data []byte, read func(body io.Reader, size int64) error) error {
+	var (
+		attempts = 1
+		backoff  = o.retryBackoff
+		breaker  = o.breaker(req)
+	)
+
+	if req.kind != requestWrite || call.idempotencyKey != "" {
+		attempts += o.retryAttempts
+	}
+
+	body, err := o.requestBody(data)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 0; ; attempt++ {
+		release, err := o.throttle(call.ctx, req.kind.class())
+		if err != nil {
+			return err
+		}
+
+		if breaker != nil {
+			if err = breaker.allow(); err != nil {
+				release()
+				return err
+			}
+		}
+
+		var (
+			info    = RequestInfo{RequestId: call.requestId, Method: req.method, URL: req.url, Attempt: attempt + 1}
+			started = time.Now()
+		)
+
+		call.attempts++
+		err = o.send(call, req, body, read, &info)
+		release()
+
+		if o.debugHook != nil {
+			info.Duration, info.Err = time.Since(started), o.redactError(err)
+			o.debugHook(info)
+		}
+
+		if breaker != nil {
+			breaker.record(breakerOutcomeOf(err))
+		}
+
+		if err == nil || attempt+1 >= attempts || !isRetryable(err) {
+			return o.redactError(err)
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-call.ctx.Done():
+			return call.ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// callTimeout returns WithCallTimeout of the call or WithTimeout of the client
+func (o *ObjectFunction) callTimeout(call *callOptions) time.Duration {
+	if call.timeout > 0 {
+		return call.timeout
+	}
+	return o.timeout
+}
+
+func (o *ObjectFunction) send(call *callOptions, req apiRequest, body requestBody, read func(body io.Reader, size int64) error, info *RequestInfo) error {
+	var ctx = call.ctx
+	if timeout := o.callTimeout(call); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, req.method, req.url, bytes.NewReader(body.data))
+	if err != nil {
+		return err
+	}
+
+	info.RequestBytes, info.RequestWireBytes = int64(body.size), int64(len(body.data))
+
+	if body.encoding != "" {
+		request.Header.Set("Content-Encoding", body.encoding)
+	}
+
+	// setting Accept-Encoding turns off transparent decoding of http.Transport, responseBody decodes instead
+	if !o.noResponseCompression {
+		request.Header.Set("Accept-Encoding", "gzip, deflate")
+	}
+
+	request.Header.Add("authorization", "API-KEY")
+	request.Header.Add("X-API-KEY", call.appId)
+
+	if o.userAgent != "" {
+		request.Header.Set("User-Agent", o.userAgent)
+	}
+
+	for key, value := range o.headers {
+		request.Header.Set(key, value)
+	}
+
+	if call.idempotencyKey != "" {
+		request.Header.Set(IdempotencyKeyHeader, call.idempotencyKey)
+	}
+
+	if call.requestId != "" {
+		request.Header.Set(RequestIdHeader, call.requestId)
+	}
+
+	for key, value := range call.headers {
+		request.Header.Set(key, value)
+	}
+
+	var client = o.httpClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	info.StatusCode = resp.StatusCode
+	call.statusCode, call.serverRequestId = resp.StatusCode, resp.Header.Get(RequestIdHeader)
+
+	respBody, size, err := responseBody(resp, info)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode > 300 {
+		respByte, err := io.ReadAll(respBody)
+		var message = string(respByte)
+		if err != nil {
+			message += err.Error()
+		}
+		return &ResponseError{StatusCode: resp.StatusCode, ErrorMessage: message}
+	}
+
+	return read(respBody, size)
+}
+
+// requestData encodes request body, requests without body (GET) are sent empty
+func (o *ObjectFunction) requestData(req apiRequest) ([]byte, error) {
+	if req.body == nil {
+		return nil, nil
+	}
+
+	data, err := o.marshal(req.body)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling request: %v", err)
+	}
+
+	return data, nil
+}
+
+func isRetryable(err error) bool {
+	var responseErr *ResponseError
+	if errors.As(err, &responseErr) {
+		return responseErr.StatusCode == http.StatusTooManyRequests || responseErr.StatusCode >= 500
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,97 @@
+package ucodesdk
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// CoalesceStats counts read calls and writes with idempotency key that went through coalescing
+type CoalesceStats struct {
+	// Calls is number of coalesced calls, including the ones that sent the request
+	Calls int64
+	// Deduplicated is number of calls that shared the result of another in-flight request
+	Deduplicated int64
+}
+
+// WithCoalescing enables or disables sharing of identical concurrent read requests, it is enabled by default
+func WithCoalescing(enabled bool) Option {
+	return func(o *ObjectFunction) {
+		o.noCoalesce = !enabled
+	}
+}
+
+// WithCallNoCoalesce sends the call's own request even when identical one is in flight
+func WithCallNoCoalesce() CallOption {
+	return func(c *callOptions) {
+		c.noCoalesce = true
+	}
+}
+
+// CoalesceStats returns counters of coalesced read calls since the client was created
+func (o *ObjectFunction) CoalesceStats() CoalesceStats {
+	return CoalesceStats{
+		Calls:        o.inflight.calls.Load(),
+		Deduplicated: o.inflight.deduplicated.Load(),
+	}
+}
+
+// coalescer shares result of in-flight request with identical calls made while it runs
+type coalescer struct {
+	mu      sync.Mutex
+	pending map[string]*inflightCall
+
+	calls        atomic.Int64
+	deduplicated atomic.Int64
+}
+
+type inflightCall struct {
+	done     chan struct{}
+	respByte []byte
+	err      error
+}
+
+/*
+do runs fn once for concurrent calls with the same key. Waiting calls return when their own
+context is done. When the request was cancelled by context of the call that sent it,
+waiting calls with live context send their own request instead of getting its error.
+*/
+func (c *coalescer) do(ctx context.Context, key string, fn func() ([]byte, error)) ([]byte, error) {
+	c.calls.Add(1)
+
+	c.mu.Lock()
+	if c.pending == nil {
+		c.pending = map[string]*inflightCall{}
+	}
+
+	if call, ok := c.pending[key]; ok {
+		c.mu.Unlock()
+		c.deduplicated.Add(1)
+
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		if errors.Is(call.err, context.Canceled) || errors.Is(call.err, context.DeadlineExceeded) {
+			return fn()
+		}
+		return call.respByte, call.err
+	}
+
+	var call = &inflightCall{done: make(chan struct{})}
+	c.pending[key] = call
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, key)
+		c.mu.Unlock()
+		close(call.done)
+	}()
+
+	call.respByte, call.err = fn()
+	return call.respByte, call.err
+}
@@ -0,0 +1,55 @@
+package ucodesdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCoalescedCallTimeout(t *testing.T) {
+	var (
+		started = make(chan struct{}, 1)
+		release = make(chan struct{})
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.Write([]byte(`{"data": {"data": {"response": {"guid": "guid-1"}}}}`))
+	}))
+	defer server.Close()
+	defer close(release)
+
+	var (
+		o    = New(&Config{BaseURL: server.URL, AppId: "app"})
+		arg  = &Argument{TableSlug: "order", Request: Request{Data: map[string]interface{}{"guid": "guid-1"}}}
+		done = make(chan error, 1)
+	)
+
+	go func() {
+		_, _, err := o.GetSingle(arg)
+		done <- err
+	}()
+	<-started
+
+	var begin = time.Now()
+	_, _, err := o.GetSingle(arg, WithCallTimeout(50*time.Millisecond))
+	if elapsed := time.Since(begin); elapsed > 300*time.Millisecond {
+		t.Errorf("call waited %v for in-flight request, longer than its timeout", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error is %v, want deadline exceeded", err)
+	}
+
+	if stats := o.CoalesceStats(); stats.Deduplicated != 1 {
+		t.Errorf("%d calls joined in-flight request, want 1", stats.Deduplicated)
+	}
+
+	release <- struct{}{}
+	if err := <-done; err != nil {
+		t.Errorf("leading call failed: %v", err)
+	}
+}
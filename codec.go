@@ -0,0 +1,131 @@
+package ucodesdk
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+/*
+Codec encodes request bodies and decodes responses of u-code API calls.
+JSONCodec wrapping encoding/json is used by default, a faster JSON library with the same
+semantics can be plugged in with WithCodec. Streaming methods always use encoding/json decoder.
+*/
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONCodec is Codec of encoding/json
+type JSONCodec struct{}
+
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// WithCodec replaces JSONCodec used for request and response bodies
+func WithCodec(codec Codec) Option {
+	return func(o *ObjectFunction) {
+		o.codec = codec
+	}
+}
+
+func (o *ObjectFunction) marshal(v interface{}) ([]byte, error) {
+	if o.codec == nil {
+		return json.Marshal(v)
+	}
+	return o.codec.Marshal(v)
+}
+
+func (o *ObjectFunction) unmarshal(data []byte, v interface{}) error {
+	if o.codec == nil {
+		return json.Unmarshal(data, v)
+	}
+	return o.codec.Unmarshal(data, v)
+}
+
+// maxPooledBuffer keeps buffers of huge responses out of the pool so they can be collected
+const maxPooledBuffer = 1 << 20
+
+var (
+	bufferPool = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+	gzipPool   = sync.Pool{New: func() interface{} { return gzip.NewWriter(io.Discard) }}
+)
+
+/*
+readBody reads whole body. Known size is allocated once, otherwise body is read into pooled buffer
+and copied out, so growing buffer is not reallocated for every response. Returned slice is not
+pooled, it is cached and shared between coalesced calls.
+*/
+func readBody(body io.Reader, size int64) ([]byte, error) {
+	if size > 0 && size <= maxPooledBuffer*64 {
+		var data = make([]byte, size)
+		n, err := io.ReadFull(body, data)
+		if err != nil {
+			return data[:n], err
+		}
+
+		// body longer than announced is read to the end as io.ReadAll would do
+		rest, err := io.ReadAll(body)
+		return append(data, rest...), err
+	}
+
+	var buf = bufferPool.Get().(*bytes.Buffer)
+	defer putBuffer(buf)
+
+	_, err := buf.ReadFrom(body)
+	return bytes.Clone(buf.Bytes()), err
+}
+
+// putBuffer returns buffer to the pool unless it grew too big
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() <= maxPooledBuffer {
+		buf.Reset()
+		bufferPool.Put(buf)
+	}
+}
+
+// queryEscape writes data escaped like url.QueryEscape without converting it to string first
+func queryEscape(buf *bytes.Buffer, data []byte) {
+	const hex = "0123456789ABCDEF"
+
+	for _, c := range data {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			buf.WriteByte(c)
+		case c == ' ':
+			buf.WriteByte('+')
+		default:
+			buf.WriteByte('%')
+			buf.WriteByte(hex[c>>4])
+			buf.WriteByte(hex[c&15])
+		}
+	}
+}
+
+// gzipBytes compresses data with pooled writer, output is a fresh slice since http.Transport
+// may read request body after the response is returned
+func gzipBytes(data []byte) ([]byte, error) {
+	var (
+		buf    = bytes.NewBuffer(make([]byte, 0, len(data)/4+64))
+		writer = gzipPool.Get().(*gzip.Writer)
+	)
+	defer gzipPool.Put(writer)
+
+	writer.Reset(buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
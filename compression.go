@@ -0,0 +1,137 @@
+package ucodesdk
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RequestInfo describes one attempt of u-code API request, it is passed to debug hook
+type RequestInfo struct {
+	RequestId  string
+	Method     string
+	URL        string
+	Attempt    int
+	StatusCode int
+	Duration   time.Duration
+	Err        error
+
+	// RequestBytes is size of request body before compression, RequestWireBytes is size sent
+	RequestBytes     int64
+	RequestWireBytes int64
+	// ResponseBytes is size of decoded response body, ResponseWireBytes is size received
+	ResponseBytes     int64
+	ResponseWireBytes int64
+	// ContentEncoding is encoding of the response body
+	ContentEncoding string
+}
+
+// WithDebugHook calls hook after every attempt of u-code API request, errors in info are redacted
+func WithDebugHook(hook func(info RequestInfo)) Option {
+	return func(o *ObjectFunction) {
+		o.debugHook = hook
+	}
+}
+
+// WithRequestCompression gzips request bodies of at least threshold bytes, e.g. bulk MultipleUpsert
+func WithRequestCompression(threshold int) Option {
+	return func(o *ObjectFunction) {
+		o.gzipThreshold = threshold
+	}
+}
+
+// WithResponseCompression enables or disables gzip and deflate responses, they are enabled by default
+func WithResponseCompression(enabled bool) Option {
+	return func(o *ObjectFunction) {
+		o.noResponseCompression = !enabled
+	}
+}
+
+// requestBody is request body prepared for sending
+type requestBody struct {
+	data     []byte
+	size     int
+	encoding string
+}
+
+func (o *ObjectFunction) requestBody(data []byte) (requestBody, error) {
+	var body = requestBody{data: data, size: len(data)}
+
+	if o.gzipThreshold <= 0 || len(data) < o.gzipThreshold {
+		return body, nil
+	}
+
+	compressed, err := gzipBytes(data)
+	if err != nil {
+		return body, fmt.Errorf("error compressing request body: %v", err)
+	}
+
+	body.data, body.encoding = compressed, "gzip"
+	return body, nil
+}
+
+// responseBody decodes body by Content-Encoding of resp, wire and decoded bytes are counted into info.
+// Decoded size is returned when it is known from Content-Length, -1 otherwise.
+func responseBody(resp *http.Response, info *RequestInfo) (io.Reader, int64, error) {
+	var wire io.Reader = &countingReader{reader: resp.Body, count: &info.ResponseWireBytes}
+
+	var (
+		decoded io.Reader
+		size    int64 = -1
+	)
+
+	switch encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))); encoding {
+	case "", "identity":
+		decoded, size = wire, resp.ContentLength
+	case "gzip", "x-gzip":
+		reader, err := gzip.NewReader(wire)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error decoding gzip response: %v", err)
+		}
+		decoded = reader
+	case "deflate":
+		reader, err := deflateReader(wire)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error decoding deflate response: %v", err)
+		}
+		decoded = reader
+	default:
+		return nil, 0, fmt.Errorf("unsupported response encoding %s", encoding)
+	}
+
+	info.ContentEncoding = resp.Header.Get("Content-Encoding")
+	return &countingReader{reader: decoded, count: &info.ResponseBytes}, size, nil
+}
+
+// deflateReader reads zlib stream as the standard says, raw deflate sent by some servers is accepted too
+func deflateReader(reader io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(reader)
+
+	header, err := buffered.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(buffered)
+	}
+
+	return flate.NewReader(buffered), nil
+}
+
+type countingReader struct {
+	reader io.Reader
+	count  *int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	*r.count += int64(n)
+	return n, err
+}
@@ -1,5 +1,26 @@
 package ucodesdk
 
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix is prepended to upper cased config keys to get environment variable names,
+// e.g. key "app_id" is read from UCODE_APP_ID
+const EnvPrefix = "UCODE_"
+
 type Config struct {
 	AppId          string
 	BaseURL        string
@@ -7,6 +28,50 @@ type Config struct {
 	AccountIds     []string
 	FunctionName   string
 	FirebaseConfig string
+
+	// Secret sources resolved lazily when AppId, BotToken or FirebaseConfig is empty,
+	// wrap them with CachedSecret to avoid resolving on every call
+	AppIdSource          SecretSource
+	BotTokenSource       SecretSource
+	FirebaseConfigSource SecretSource
+
+	// FirebaseConfigEnv is the name of environment variable holding service account JSON,
+	// used when FirebaseConfig is empty
+	FirebaseConfigEnv string
+	// FirebaseConfigPath is the path to service account JSON file,
+	// used when FirebaseConfig and FirebaseConfigEnv are empty
+	FirebaseConfigPath string
+	// FirebaseEndpoint overrides FCM endpoint, e.g. local emulator or stub server.
+	// Without credentials requests are sent unauthenticated and FirebaseProjectId is required
+	FirebaseEndpoint  string
+	FirebaseProjectId string
+	// FirebaseDryRun validates notifications on FCM without delivering them
+	FirebaseDryRun bool
+
+	// SMTP settings used by SendEmail. SmtpTLS dials with implicit TLS (port 465),
+	// SmtpStartTLS upgrades plain connection (port 587). Auth is skipped when SmtpUsername is empty
+	SmtpHost     string
+	SmtpPort     int
+	SmtpUsername string
+	SmtpPassword string
+	SmtpFrom     string
+	SmtpTLS      bool
+	SmtpStartTLS bool
+
+	// Webhook settings used by SendWebhook and SendSlack. Requests are signed with
+	// HMAC-SHA256 of WebhookSecret when it is set
+	WebhookURLs      []string
+	WebhookSecret    string
+	WebhookHeaders   map[string]string
+	WebhookTimeout   time.Duration
+	WebhookRetries   int
+	SlackWebhookURLs []string
+
+	// Async settings of dispatcher used by Send*Async methods, see DispatcherConfig
+	AsyncQueueSize int
+	AsyncWorkers   int
+	AsyncPolicy    DispatchPolicy
+	AsyncRetries   int
 }
 
 func (cfg *Config) SetAppId(appId string) {
@@ -20,3 +85,432 @@ func (cfg *Config) SetBaseUrl(url string) {
 func (cfg *Config) SetBotToken(token string) {
 	cfg.BotToken = token
 }
+
+type configField struct {
+	key string
+	set func(cfg *Config, value interface{}) error
+}
+
+// configFields lists keys of JSON and YAML config files, environment names are EnvPrefix + upper cased key
+var configFields = []configField{
+	{"app_id", setString(func(cfg *Config) *string { return &cfg.AppId })},
+	{"base_url", setString(func(cfg *Config) *string { return &cfg.BaseURL })},
+	{"bot_token", setString(func(cfg *Config) *string { return &cfg.BotToken })},
+	{"app_id_file", setFileSecret(func(cfg *Config) *SecretSource { return &cfg.AppIdSource })},
+	{"bot_token_file", setFileSecret(func(cfg *Config) *SecretSource { return &cfg.BotTokenSource })},
+	{"account_ids", setStrings(func(cfg *Config) *[]string { return &cfg.AccountIds })},
+	{"function_name", setString(func(cfg *Config) *string { return &cfg.FunctionName })},
+	{"firebase_config", setJSONString(func(cfg *Config) *string { return &cfg.FirebaseConfig })},
+	{"firebase_config_env", setString(func(cfg *Config) *string { return &cfg.FirebaseConfigEnv })},
+	{"firebase_config_path", setString(func(cfg *Config) *string { return &cfg.FirebaseConfigPath })},
+	{"firebase_endpoint", setString(func(cfg *Config) *string { return &cfg.FirebaseEndpoint })},
+	{"firebase_project_id", setString(func(cfg *Config) *string { return &cfg.FirebaseProjectId })},
+	{"firebase_dry_run", setBool(func(cfg *Config) *bool { return &cfg.FirebaseDryRun })},
+	{"smtp_host", setString(func(cfg *Config) *string { return &cfg.SmtpHost })},
+	{"smtp_port", setInt(func(cfg *Config) *int { return &cfg.SmtpPort })},
+	{"smtp_username", setString(func(cfg *Config) *string { return &cfg.SmtpUsername })},
+	{"smtp_password", setString(func(cfg *Config) *string { return &cfg.SmtpPassword })},
+	{"smtp_from", setString(func(cfg *Config) *string { return &cfg.SmtpFrom })},
+	{"smtp_tls", setBool(func(cfg *Config) *bool { return &cfg.SmtpTLS })},
+	{"smtp_starttls", setBool(func(cfg *Config) *bool { return &cfg.SmtpStartTLS })},
+	{"webhook_urls", setStrings(func(cfg *Config) *[]string { return &cfg.WebhookURLs })},
+	{"webhook_secret", setString(func(cfg *Config) *string { return &cfg.WebhookSecret })},
+	{"webhook_headers", setStringMap(func(cfg *Config) *map[string]string { return &cfg.WebhookHeaders })},
+	{"webhook_timeout", setDuration(func(cfg *Config) *time.Duration { return &cfg.WebhookTimeout })},
+	{"webhook_retries", setInt(func(cfg *Config) *int { return &cfg.WebhookRetries })},
+	{"slack_webhook_urls", setStrings(func(cfg *Config) *[]string { return &cfg.SlackWebhookURLs })},
+	{"async_queue_size", setInt(func(cfg *Config) *int { return &cfg.AsyncQueueSize })},
+	{"async_workers", setInt(func(cfg *Config) *int { return &cfg.AsyncWorkers })},
+	{"async_policy", setDispatchPolicy},
+	{"async_retries", setInt(func(cfg *Config) *int { return &cfg.AsyncRetries })},
+}
+
+/*
+LoadConfig builds Config from files and environment variables.
+Files are applied in the given order and environment variables last, so later sources override earlier ones.
+File type is chosen by extension: .env, .json, .yaml or .yml. JSON and YAML files use keys like
+"app_id", "base_url", "account_ids"; .env files and environment use UCODE_APP_ID, UCODE_BASE_URL,
+UCODE_ACCOUNT_IDS (comma separated). Null values leave the field as earlier sources set it.
+Call Validate on the result before the first request.
+*/
+func LoadConfig(files ...string) (*Config, error) {
+	var cfg = &Config{}
+
+	for _, file := range files {
+		values, err := readConfigFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = cfg.apply(values); err != nil {
+			return nil, fmt.Errorf("config %s: %v", file, err)
+		}
+	}
+
+	var env = map[string]interface{}{}
+	for _, field := range configFields {
+		if value, ok := os.LookupEnv(EnvPrefix + strings.ToUpper(field.key)); ok {
+			env[field.key] = value
+		}
+	}
+
+	if err := cfg.apply(env); err != nil {
+		return nil, fmt.Errorf("config environment: %v", err)
+	}
+
+	return cfg, nil
+}
+
+func (cfg *Config) apply(values map[string]interface{}) error {
+	for _, field := range configFields {
+		value, ok := values[field.key]
+		if !ok || value == nil {
+			continue
+		}
+
+		if err := field.set(cfg, value); err != nil {
+			return fmt.Errorf("invalid %s: %v", field.key, err)
+		}
+	}
+
+	return nil
+}
+
+func readConfigFile(file string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config: %v", err)
+	}
+
+	var values map[string]interface{}
+
+	switch ext := strings.ToLower(filepath.Ext(file)); {
+	case ext == ".json":
+		err = json.Unmarshal(data, &values)
+	case ext == ".yaml" || ext == ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ext == ".env" || filepath.Base(file) == ".env":
+		values, err = parseDotEnv(data)
+	default:
+		return nil, fmt.Errorf("unsupported config file type: %s", file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing config %s: %v", file, err)
+	}
+
+	return values, nil
+}
+
+// parseDotEnv reads KEY=VALUE lines, keeps only EnvPrefix keys and converts them to config keys
+func parseDotEnv(data []byte) (map[string]interface{}, error) {
+	var (
+		values  = map[string]interface{}{}
+		scanner = bufio.NewScanner(bytes.NewReader(data))
+		number  int
+	)
+
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		number++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", number)
+		}
+
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			if value[0] == '"' {
+				unquoted, err := strconv.Unquote(value)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: invalid quoted value", number)
+				}
+				value = unquoted
+			} else {
+				value = value[1 : len(value)-1]
+			}
+		} else {
+			value = stripComment(value)
+		}
+
+		if strings.HasPrefix(key, EnvPrefix) {
+			values[strings.ToLower(strings.TrimPrefix(key, EnvPrefix))] = value
+		}
+	}
+
+	return values, scanner.Err()
+}
+
+// stripComment cuts " # comment" from value, # inside quotes is kept
+func stripComment(value string) string {
+	var quote byte
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || value[i-1] == ' ' || value[i-1] == '\t'):
+			return strings.TrimSpace(value[:i])
+		}
+	}
+	return value
+}
+
+func setString(field func(cfg *Config) *string) func(cfg *Config, value interface{}) error {
+	return func(cfg *Config, value interface{}) error {
+		str, err := cast.ToStringE(value)
+		if err != nil {
+			return err
+		}
+		*field(cfg) = str
+		return nil
+	}
+}
+
+// setJSONString accepts JSON text or object, e.g. firebase service account inlined in JSON config
+func setJSONString(field func(cfg *Config) *string) func(cfg *Config, value interface{}) error {
+	return func(cfg *Config, value interface{}) error {
+		if object, ok := value.(map[string]interface{}); ok {
+			data, err := json.Marshal(object)
+			if err != nil {
+				return err
+			}
+			value = string(data)
+		}
+		return setString(field)(cfg, value)
+	}
+}
+
+// setFileSecret reads secret from file lazily, value is cached for a minute to pick up rotation
+func setFileSecret(field func(cfg *Config) *SecretSource) func(cfg *Config, value interface{}) error {
+	return func(cfg *Config, value interface{}) error {
+		path, err := cast.ToStringE(value)
+		if err != nil {
+			return err
+		}
+		*field(cfg) = CachedSecret(FileSecret(path), time.Minute)
+		return nil
+	}
+}
+
+// setStrings accepts list or comma separated string
+func setStrings(field func(cfg *Config) *[]string) func(cfg *Config, value interface{}) error {
+	return func(cfg *Config, value interface{}) error {
+		var result []string
+
+		if str, ok := value.(string); ok {
+			for _, item := range strings.Split(str, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					result = append(result, item)
+				}
+			}
+		} else {
+			list, err := cast.ToSliceE(value)
+			if err != nil {
+				return err
+			}
+			for _, item := range list {
+				str, err := cast.ToStringE(item)
+				if err != nil {
+					return err
+				}
+				result = append(result, str)
+			}
+		}
+
+		*field(cfg) = result
+		return nil
+	}
+}
+
+// setStringMap accepts map, JSON object text or "key=value,key2=value2"
+func setStringMap(field func(cfg *Config) *map[string]string) func(cfg *Config, value interface{}) error {
+	return func(cfg *Config, value interface{}) error {
+		var result = map[string]string{}
+
+		if str, ok := value.(string); ok && !strings.HasPrefix(strings.TrimSpace(str), "{") {
+			for _, pair := range strings.Split(str, ",") {
+				if strings.TrimSpace(pair) == "" {
+					continue
+				}
+
+				key, val, ok := strings.Cut(pair, "=")
+				if !ok {
+					return fmt.Errorf("expected key=value, got %q", pair)
+				}
+				result[strings.TrimSpace(key)] = strings.TrimSpace(val)
+			}
+		} else {
+			converted, err := cast.ToStringMapStringE(value)
+			if err != nil {
+				return err
+			}
+			result = converted
+		}
+
+		*field(cfg) = result
+		return nil
+	}
+}
+
+func setInt(field func(cfg *Config) *int) func(cfg *Config, value interface{}) error {
+	return func(cfg *Config, value interface{}) error {
+		number, err := cast.ToIntE(value)
+		if err != nil {
+			return err
+		}
+		*field(cfg) = number
+		return nil
+	}
+}
+
+func setBool(field func(cfg *Config) *bool) func(cfg *Config, value interface{}) error {
+	return func(cfg *Config, value interface{}) error {
+		flag, err := cast.ToBoolE(value)
+		if err != nil {
+			return err
+		}
+		*field(cfg) = flag
+		return nil
+	}
+}
+
+func setDuration(field func(cfg *Config) *time.Duration) func(cfg *Config, value interface{}) error {
+	return func(cfg *Config, value interface{}) error {
+		duration, err := cast.ToDurationE(value)
+		if err != nil {
+			return err
+		}
+		*field(cfg) = duration
+		return nil
+	}
+}
+
+// setDispatchPolicy accepts "block", "drop", "drop_oldest" or number of DispatchPolicy
+func setDispatchPolicy(cfg *Config, value interface{}) error {
+	switch strings.ToLower(cast.ToString(value)) {
+	case "block":
+		cfg.AsyncPolicy = DispatchBlock
+	case "drop":
+		cfg.AsyncPolicy = DispatchDrop
+	case "drop_oldest", "oldest":
+		cfg.AsyncPolicy = DispatchDropOldest
+	default:
+		number, err := cast.ToIntE(value)
+		if err != nil || number < int(DispatchBlock) || number > int(DispatchDropOldest) {
+			return fmt.Errorf("unknown policy %v", value)
+		}
+		cfg.AsyncPolicy = DispatchPolicy(number)
+	}
+	return nil
+}
+
+// ConfigError describes one invalid or missing Config field
+type ConfigError struct {
+	Field   string
+	Message string
+}
+
+func (e *ConfigError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+/*
+Validate reports every missing or invalid field at once, errors are joined *ConfigError values.
+AppId and BaseURL are required, other settings are checked only when they are used.
+*/
+func (cfg *Config) Validate() error {
+	var errs []error
+	report := func(field, format string, args ...interface{}) {
+		errs = append(errs, &ConfigError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if cfg.AppId == "" && cfg.AppIdSource == nil {
+		report("AppId", "is required")
+	}
+
+	if cfg.BaseURL == "" {
+		report("BaseURL", "is required")
+	} else if err := validateURL(cfg.BaseURL); err != nil {
+		report("BaseURL", "%v", err)
+	}
+
+	for _, id := range cfg.AccountIds {
+		if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+			report("AccountIds", "chat id %q is not a number", id)
+		}
+	}
+
+	if len(cfg.AccountIds) > 0 && cfg.BotToken == "" && cfg.BotTokenSource == nil {
+		report("BotToken", "is required when AccountIds are set")
+	}
+
+	if cfg.FirebaseConfig != "" {
+		var credentials map[string]interface{}
+		if err := json.Unmarshal([]byte(cfg.FirebaseConfig), &credentials); err != nil {
+			report("FirebaseConfig", "is not valid JSON: %v", err)
+		} else if _, ok := credentials["type"]; !ok {
+			report("FirebaseConfig", "is not a service account JSON, \"type\" is missing")
+		}
+	}
+
+	if cfg.FirebaseConfigPath != "" {
+		if _, err := os.Stat(cfg.FirebaseConfigPath); err != nil {
+			report("FirebaseConfigPath", "%v", err)
+		}
+	}
+
+	if cfg.FirebaseEndpoint != "" {
+		if err := validateURL(cfg.FirebaseEndpoint); err != nil {
+			report("FirebaseEndpoint", "%v", err)
+		}
+	}
+
+	if cfg.SmtpPort < 0 || cfg.SmtpPort > 65535 {
+		report("SmtpPort", "%d is out of range", cfg.SmtpPort)
+	}
+
+	if cfg.SmtpHost != "" && cfg.SmtpFrom == "" {
+		report("SmtpFrom", "is required when SmtpHost is set")
+	}
+
+	if cfg.SmtpTLS && cfg.SmtpStartTLS {
+		report("SmtpStartTLS", "can't be used together with SmtpTLS")
+	}
+
+	for _, webhook := range append(append([]string{}, cfg.WebhookURLs...), cfg.SlackWebhookURLs...) {
+		if err := validateURL(webhook); err != nil {
+			report("WebhookURLs", "%v", err)
+		}
+	}
+
+	if cfg.WebhookTimeout < 0 {
+		report("WebhookTimeout", "must not be negative")
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("malformed url %q: %v", raw, err)
+	}
+
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("url %q must start with http:// or https://", raw)
+	}
+
+	if parsed.Host == "" {
+		return fmt.Errorf("url %q has no host", raw)
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package ucodesdk
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	var path = filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	var (
+		jsonFile = writeConfigFile(t, "config.json", `{"app_id": "json-app", "base_url": "https://json.example.com", "smtp_port": 25, "account_ids": ["1", "2"]}`)
+		yamlFile = writeConfigFile(t, "config.yaml", "base_url: https://yaml.example.com\nsmtp_port: 587\n")
+		envFile  = writeConfigFile(t, ".env", "UCODE_SMTP_PORT=465\nUCODE_BOT_TOKEN=dotenv-token\n")
+	)
+
+	t.Setenv("UCODE_BOT_TOKEN", "env-token")
+
+	cfg, err := LoadConfig(jsonFile, yamlFile, envFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.AppId != "json-app" {
+		t.Errorf("AppId is %q, want value of the only file setting it", cfg.AppId)
+	}
+	if cfg.BaseURL != "https://yaml.example.com" {
+		t.Errorf("BaseURL is %q, want value of the later file", cfg.BaseURL)
+	}
+	if cfg.SmtpPort != 465 {
+		t.Errorf("SmtpPort is %d, want value of the last file", cfg.SmtpPort)
+	}
+	if cfg.BotToken != "env-token" {
+		t.Errorf("BotToken is %q, want environment value", cfg.BotToken)
+	}
+	if !reflect.DeepEqual(cfg.AccountIds, []string{"1", "2"}) {
+		t.Errorf("AccountIds are %v", cfg.AccountIds)
+	}
+}
+
+func TestLoadConfigYAML(t *testing.T) {
+	var file = writeConfigFile(t, "config.yml", `
+app_id: app # comment
+base_url: "https://api.example.com"
+account_ids: ['1,2', 3]
+webhook_headers:
+  Authorization: Bearer token
+  X-Source: sdk
+webhook_timeout: 5s
+smtp_tls: true
+firebase_config:
+  type: service_account
+  project_id: project
+  nested:
+    deep:
+      x: 1
+bot_token: ~
+smtp_from: null
+`)
+
+	cfg, err := LoadConfig(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.AppId != "app" || cfg.BaseURL != "https://api.example.com" {
+		t.Errorf("AppId, BaseURL are %q, %q", cfg.AppId, cfg.BaseURL)
+	}
+	if !reflect.DeepEqual(cfg.AccountIds, []string{"1,2", "3"}) {
+		t.Errorf("AccountIds are %q", cfg.AccountIds)
+	}
+	if !reflect.DeepEqual(cfg.WebhookHeaders, map[string]string{"Authorization": "Bearer token", "X-Source": "sdk"}) {
+		t.Errorf("WebhookHeaders are %v", cfg.WebhookHeaders)
+	}
+	if cfg.WebhookTimeout != 5*time.Second || !cfg.SmtpTLS {
+		t.Errorf("WebhookTimeout, SmtpTLS are %v, %v", cfg.WebhookTimeout, cfg.SmtpTLS)
+	}
+	if cfg.FirebaseConfig != `{"nested":{"deep":{"x":1}},"project_id":"project","type":"service_account"}` {
+		t.Errorf("FirebaseConfig is %s", cfg.FirebaseConfig)
+	}
+	if cfg.BotToken != "" || cfg.SmtpFrom != "" {
+		t.Errorf("null values are set: %q, %q", cfg.BotToken, cfg.SmtpFrom)
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	var files = []string{
+		writeConfigFile(t, "bad.yaml", "app_id: [unclosed\n"),
+		writeConfigFile(t, "tabs.yaml", "app_id: app\n\tbase_url: x\n"),
+		writeConfigFile(t, "port.yaml", "smtp_port: many\n"),
+		writeConfigFile(t, "bad.env", "UCODE_APP_ID\n"),
+		writeConfigFile(t, "config.toml", "app_id = 'app'\n"),
+	}
+
+	for _, file := range files {
+		if _, err := LoadConfig(file); err == nil {
+			t.Errorf("%s loaded without error", filepath.Base(file))
+		}
+	}
+}
+
+func TestParseDotEnv(t *testing.T) {
+	values, err := parseDotEnv([]byte(`
+# comment
+UCODE_APP_ID=app # trailing comment
+export UCODE_BASE_URL="https://api.example.com/#fragment"
+UCODE_BOT_TOKEN='token # kept'
+OTHER_KEY=ignored
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want = map[string]interface{}{
+		"app_id":    "app",
+		"base_url":  "https://api.example.com/#fragment",
+		"bot_token": "token # kept",
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("values are %v, want %v", values, want)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	if err := (&Config{AppId: "app", BaseURL: "https://api.example.com"}).Validate(); err != nil {
+		t.Errorf("valid config: %v", err)
+	}
+
+	var cfg = &Config{
+		BaseURL:        "ftp://api.example.com",
+		AccountIds:     []string{"chat"},
+		SmtpHost:       "smtp.example.com",
+		SmtpPort:       70000,
+		FirebaseConfig: "{",
+	}
+
+	err := cfg.Validate()
+
+	var fields = map[string]bool{}
+	for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var configErr *ConfigError
+		if !errors.As(err, &configErr) {
+			t.Fatalf("error %v is not *ConfigError", err)
+		}
+		fields[configErr.Field] = true
+	}
+
+	for _, field := range []string{"AppId", "BaseURL", "AccountIds", "BotToken", "SmtpPort", "SmtpFrom", "FirebaseConfig"} {
+		if !fields[field] {
+			t.Errorf("%s is not reported", field)
+		}
+	}
+}
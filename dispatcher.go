@@ -0,0 +1,296 @@
+package ucodesdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DispatchPolicy decides what Dispatcher does when its queue is full
+type DispatchPolicy int
+
+const (
+	// DispatchBlock waits until queue has free space
+	DispatchBlock DispatchPolicy = iota
+	// DispatchDrop drops the new job
+	DispatchDrop
+	// DispatchDropOldest drops the oldest queued job to make space for the new one
+	DispatchDropOldest
+)
+
+var (
+	ErrDispatcherClosed = errors.New("dispatcher is closed")
+	ErrDispatchDropped  = errors.New("job dropped, dispatcher queue is full")
+)
+
+type DispatcherConfig struct {
+	// QueueSize is the number of jobs waiting for workers, default 100
+	QueueSize int
+	// Workers is the number of goroutines delivering jobs, default 2
+	Workers int
+	Policy  DispatchPolicy
+	// Retries is how many times failed job is repeated, RetryDelay doubles after every attempt
+	Retries    int
+	RetryDelay time.Duration
+	// OnError receives errors of jobs that failed after all retries and dropped jobs
+	OnError func(err error)
+}
+
+/*
+Dispatcher runs jobs on background workers with bounded queue.
+FaaS runtime must call Flush or Close before returning from the function,
+otherwise queued jobs are lost when the container is frozen.
+*/
+type Dispatcher struct {
+	cfg   DispatcherConfig
+	queue chan func() error
+
+	mu      sync.RWMutex
+	closed  bool
+	workers sync.WaitGroup
+
+	pendingMu sync.Mutex
+	pending   int
+	idle      chan struct{}
+}
+
+func NewDispatcher(cfg DispatcherConfig) *Dispatcher {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 100
+	}
+
+	if cfg.Workers <= 0 {
+		cfg.Workers = 2
+	}
+
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = 200 * time.Millisecond
+	}
+
+	d := &Dispatcher{
+		cfg:   cfg,
+		queue: make(chan func() error, cfg.QueueSize),
+		idle:  make(chan struct{}),
+	}
+	close(d.idle)
+
+	for i := 0; i < cfg.Workers; i++ {
+		d.workers.Add(1)
+		go d.work()
+	}
+
+	return d
+}
+
+// Enqueue adds job to the queue according to DispatchPolicy.
+// ErrDispatchDropped is returned when DispatchDrop rejects the job.
+func (d *Dispatcher) Enqueue(job func() error) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.closed {
+		return ErrDispatcherClosed
+	}
+
+	d.addPending(1)
+
+	switch d.cfg.Policy {
+	case DispatchDrop:
+		select {
+		case d.queue <- job:
+		default:
+			d.addPending(-1)
+			return ErrDispatchDropped
+		}
+	case DispatchDropOldest:
+		for {
+			select {
+			case d.queue <- job:
+				return nil
+			default:
+			}
+
+			select {
+			case <-d.queue:
+				d.addPending(-1)
+				d.reportError(ErrDispatchDropped)
+			default:
+			}
+		}
+	default:
+		d.queue <- job
+	}
+
+	return nil
+}
+
+// Flush waits until every queued and running job is finished or ctx is done.
+func (d *Dispatcher) Flush(ctx context.Context) error {
+	d.pendingMu.Lock()
+	idle := d.idle
+	d.pendingMu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting jobs, delivers everything already queued and stops workers.
+// It is safe to call Close more than once.
+func (d *Dispatcher) Close() {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return
+	}
+	d.closed = true
+	close(d.queue)
+	d.mu.Unlock()
+
+	d.workers.Wait()
+}
+
+// Pending returns number of queued and running jobs
+func (d *Dispatcher) Pending() int {
+	d.pendingMu.Lock()
+	defer d.pendingMu.Unlock()
+
+	return d.pending
+}
+
+func (d *Dispatcher) work() {
+	defer d.workers.Done()
+
+	for job := range d.queue {
+		if err := d.run(job); err != nil {
+			d.reportError(err)
+		}
+		d.addPending(-1)
+	}
+}
+
+func (d *Dispatcher) run(job func() error) error {
+	var (
+		delay = d.cfg.RetryDelay
+		err   error
+	)
+
+	for attempt := 0; attempt <= d.cfg.Retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+
+		if err = runJob(job); err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
+
+// runJob returns panic of job as error, so one job can not crash the function process
+func runJob(job func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("async job panicked: %v", r)
+		}
+	}()
+
+	return job()
+}
+
+func (d *Dispatcher) addPending(delta int) {
+	d.pendingMu.Lock()
+	defer d.pendingMu.Unlock()
+
+	if d.pending == 0 && delta > 0 {
+		d.idle = make(chan struct{})
+	}
+
+	d.pending += delta
+
+	if d.pending == 0 {
+		close(d.idle)
+	}
+}
+
+func (d *Dispatcher) reportError(err error) {
+	if d.cfg.OnError != nil {
+		d.cfg.OnError(err)
+	}
+}
+
+// Dispatcher returns dispatcher of ObjectFunction, it is created from Config on first use.
+// Failed deliveries are printed with ErrorLog.
+func (o *ObjectFunction) Dispatcher() *Dispatcher {
+	o.dispatcherMu.Lock()
+	defer o.dispatcherMu.Unlock()
+
+	if o.dispatcher == nil {
+		o.dispatcher = NewDispatcher(DispatcherConfig{
+			QueueSize: o.Cfg.AsyncQueueSize,
+			Workers:   o.Cfg.AsyncWorkers,
+			Policy:    o.Cfg.AsyncPolicy,
+			Retries:   o.Cfg.AsyncRetries,
+			OnError: func(err error) {
+				if o.Logger != nil {
+					fmt.Print(o.Logger.ErrorLog.Sprint("async delivery failed:", o.redactError(err)))
+				}
+			},
+		})
+	}
+
+	return o.dispatcher
+}
+
+func (o *ObjectFunction) SendTelegramAsync(text string) error {
+	return o.Dispatcher().Enqueue(func() error { return o.SendTelegramV2(text) })
+}
+
+func (o *ObjectFunction) SendNotificationAsync(notification Notification) error {
+	return o.Dispatcher().Enqueue(func() error { return o.SendNotification(notification) })
+}
+
+func (o *ObjectFunction) SendEmailAsync(email Email) error {
+	return o.Dispatcher().Enqueue(func() error { return o.SendEmail(email) })
+}
+
+func (o *ObjectFunction) SendWebhookAsync(payload interface{}) error {
+	return o.Dispatcher().Enqueue(func() error { return o.SendWebhook(payload) })
+}
+
+func (o *ObjectFunction) SendSlackAsync(text string) error {
+	return o.Dispatcher().Enqueue(func() error { return o.SendSlack(text) })
+}
+
+// Flush waits for queued async deliveries, call it before returning from the function.
+func (o *ObjectFunction) Flush(ctx context.Context) error {
+	o.dispatcherMu.Lock()
+	dispatcher := o.dispatcher
+	o.dispatcherMu.Unlock()
+
+	if dispatcher == nil {
+		return nil
+	}
+
+	return dispatcher.Flush(ctx)
+}
+
+// Close delivers queued async messages and stops dispatcher workers.
+// Warm instance keeps working, next async call creates new dispatcher.
+func (o *ObjectFunction) Close() {
+	o.dispatcherMu.Lock()
+	dispatcher := o.dispatcher
+	o.dispatcher = nil
+	o.dispatcherMu.Unlock()
+
+	if dispatcher != nil {
+		dispatcher.Close()
+	}
+}
@@ -0,0 +1,325 @@
+package ucodesdk
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	htmlTemplate "html/template"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+/*
+SendEmail sends email through SMTP server configured in Config.
+Recipients from To, Cc and Bcc all receive the message, Bcc is not written to headers.
+Attachments are sent from bytes, no temporary files are created.
+*/
+func (o *ObjectFunction) SendEmail(email Email) (err error) {
+	defer func() { err = o.redactError(err) }()
+
+	if o.Cfg.SmtpHost == "" {
+		return errors.New("smtp host is not configured")
+	}
+
+	var recipients = append(append(append([]string{}, email.To...), email.Cc...), email.Bcc...)
+	if len(recipients) == 0 {
+		return errors.New("email has no recipients")
+	}
+
+	// SMTP envelope takes bare addresses, "Name <address>" is kept for headers only
+	sender, err := mail.ParseAddress(o.Cfg.SmtpFrom)
+	if err != nil {
+		return fmt.Errorf("error parsing email sender %s: %v", o.Cfg.SmtpFrom, err)
+	}
+
+	for i, recipient := range recipients {
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return fmt.Errorf("error parsing email recipient %s: %v", recipient, err)
+		}
+		recipients[i] = address.Address
+	}
+
+	if email.TemplateData != nil {
+		if err = executeEmailTemplates(&email); err != nil {
+			return err
+		}
+	}
+
+	message, err := buildEmail(o.Cfg.SmtpFrom, email)
+	if err != nil {
+		return fmt.Errorf("error building email: %v", err)
+	}
+
+	client, err := o.smtpClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err = client.Mail(sender.Address); err != nil {
+		return fmt.Errorf("error setting email sender: %v", err)
+	}
+
+	for _, recipient := range recipients {
+		if err = client.Rcpt(recipient); err != nil {
+			return fmt.Errorf("error adding email recipient %s: %v", recipient, err)
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("error starting email data: %v", err)
+	}
+
+	if _, err = writer.Write(message); err != nil {
+		return fmt.Errorf("error writing email: %v", err)
+	}
+
+	if err = writer.Close(); err != nil {
+		return fmt.Errorf("error sending email: %v", err)
+	}
+
+	return client.Quit()
+}
+
+func (o *ObjectFunction) smtpClient() (*smtp.Client, error) {
+	var port = o.Cfg.SmtpPort
+	if port == 0 {
+		switch {
+		case o.Cfg.SmtpTLS:
+			port = 465
+		case o.Cfg.SmtpStartTLS:
+			port = 587
+		default:
+			port = 25
+		}
+	}
+
+	var (
+		address   = net.JoinHostPort(o.Cfg.SmtpHost, strconv.Itoa(port))
+		tlsConfig = &tls.Config{ServerName: o.Cfg.SmtpHost}
+		conn      net.Conn
+		err       error
+	)
+
+	if o.Cfg.SmtpTLS {
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: 10 * time.Second}, "tcp", address, tlsConfig)
+	} else {
+		conn, err = net.DialTimeout("tcp", address, 10*time.Second)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to smtp server: %v", err)
+	}
+
+	client, err := smtp.NewClient(conn, o.Cfg.SmtpHost)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error creating smtp client: %v", err)
+	}
+
+	if o.Cfg.SmtpStartTLS && !o.Cfg.SmtpTLS {
+		if err = client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("error starting tls: %v", err)
+		}
+	}
+
+	if o.Cfg.SmtpUsername != "" {
+		auth := smtp.PlainAuth("", o.Cfg.SmtpUsername, o.Cfg.SmtpPassword, o.Cfg.SmtpHost)
+		if err = client.Auth(auth); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("error authenticating to smtp server: %v", err)
+		}
+	}
+
+	return client, nil
+}
+
+func executeEmailTemplates(email *Email) error {
+	var buf bytes.Buffer
+
+	for _, field := range []*string{&email.Subject, &email.Text} {
+		if *field == "" {
+			continue
+		}
+
+		tmpl, err := template.New("email").Parse(*field)
+		if err != nil {
+			return fmt.Errorf("error parsing email template: %v", err)
+		}
+
+		buf.Reset()
+		if err = tmpl.Execute(&buf, email.TemplateData); err != nil {
+			return fmt.Errorf("error executing email template: %v", err)
+		}
+		*field = buf.String()
+	}
+
+	if email.HTML != "" {
+		tmpl, err := htmlTemplate.New("email").Parse(email.HTML)
+		if err != nil {
+			return fmt.Errorf("error parsing email html template: %v", err)
+		}
+
+		buf.Reset()
+		if err = tmpl.Execute(&buf, email.TemplateData); err != nil {
+			return fmt.Errorf("error executing email html template: %v", err)
+		}
+		email.HTML = buf.String()
+	}
+
+	return nil
+}
+
+// buildEmail renders RFC 5322 message:
+// multipart/mixed [ multipart/alternative [ text/plain, text/html ], attachments... ]
+func buildEmail(from string, email Email) ([]byte, error) {
+	var (
+		buf    bytes.Buffer
+		header = textproto.MIMEHeader{}
+	)
+
+	header.Set("From", from)
+	header.Set("To", strings.Join(email.To, ", "))
+	if len(email.Cc) > 0 {
+		header.Set("Cc", strings.Join(email.Cc, ", "))
+	}
+	if email.ReplyTo != "" {
+		header.Set("Reply-To", email.ReplyTo)
+	}
+
+	// line break in address would start a new header, e.g. hidden Bcc
+	for _, key := range []string{"From", "To", "Cc", "Reply-To"} {
+		if strings.ContainsAny(header.Get(key), "\r\n") {
+			return nil, fmt.Errorf("%s header contains line break", key)
+		}
+	}
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", email.Subject))
+	header.Set("Date", time.Now().Format(time.RFC1123Z))
+	header.Set("Message-Id", fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), GenerateRandomString(12, LowerNumber), emailDomain(from)))
+	header.Set("MIME-Version", "1.0")
+
+	mixed := multipart.NewWriter(&buf)
+	header.Set("Content-Type", "multipart/mixed; boundary="+mixed.Boundary())
+	writeHeader(&buf, header)
+
+	var alternativeBuf bytes.Buffer
+	alternative := multipart.NewWriter(&alternativeBuf)
+
+	bodyPart, err := mixed.CreatePart(textproto.MIMEHeader{"Content-Type": {"multipart/alternative; boundary=" + alternative.Boundary()}})
+	if err != nil {
+		return nil, err
+	}
+
+	var bodies = []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", email.Text},
+		{"text/html; charset=utf-8", email.HTML},
+	}
+	for _, body := range bodies {
+		if body.content == "" {
+			continue
+		}
+
+		part, err := alternative.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {body.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		qp := quotedprintable.NewWriter(part)
+		if _, err = qp.Write([]byte(body.content)); err != nil {
+			return nil, err
+		}
+		if err = qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = alternative.Close(); err != nil {
+		return nil, err
+	}
+	if _, err = bodyPart.Write(alternativeBuf.Bytes()); err != nil {
+		return nil, err
+	}
+
+	for _, attachment := range email.Attachments {
+		var contentType = attachment.ContentType
+		if contentType == "" {
+			contentType = mime.TypeByExtension(filepath.Ext(attachment.Filename))
+		}
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		part, err := mixed.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err = writeBase64Lines(part, attachment.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = mixed.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeHeader(buf *bytes.Buffer, header textproto.MIMEHeader) {
+	for key, values := range header {
+		for _, value := range values {
+			buf.WriteString(key + ": " + value + "\r\n")
+		}
+	}
+	buf.WriteString("\r\n")
+}
+
+// writeBase64Lines writes base64 encoded data split into 76 character lines as required by RFC 2045
+func writeBase64Lines(w io.Writer, data []byte) error {
+	const lineLength = 76
+
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 0 {
+		var n = lineLength
+		if len(encoded) < n {
+			n = len(encoded)
+		}
+
+		if _, err := w.Write([]byte(encoded[:n] + "\r\n")); err != nil {
+			return err
+		}
+		encoded = encoded[n:]
+	}
+
+	return nil
+}
+
+func emailDomain(address string) string {
+	if i := strings.LastIndex(address, "@"); i >= 0 {
+		return strings.Trim(address[i+1:], "> ")
+	}
+	return "localhost"
+}
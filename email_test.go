@@ -0,0 +1,174 @@
+package ucodesdk
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// smtpStandIn is minimal SMTP server on localhost recording envelope and data of received messages
+type smtpStandIn struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	from     string
+	rcpt     []string
+	data     string
+	received chan struct{}
+}
+
+func newSmtpStandIn(t *testing.T) *smtpStandIn {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	var server = &smtpStandIn{listener: listener, received: make(chan struct{}, 1)}
+	go server.serve()
+
+	return server
+}
+
+func (s *smtpStandIn) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpStandIn) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpStandIn) handle(conn net.Conn) {
+	defer conn.Close()
+
+	var (
+		reader = bufio.NewReader(conn)
+		reply  = func(line string) { conn.Write([]byte(line + "\r\n")) }
+	)
+
+	reply("220 localhost ESMTP stand-in")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch command := strings.ToUpper(line); {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			s.mu.Lock()
+			s.from = line[len("MAIL FROM:"):]
+			s.mu.Unlock()
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			s.mu.Lock()
+			s.rcpt = append(s.rcpt, line[len("RCPT TO:"):])
+			s.mu.Unlock()
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 end data with <CR><LF>.<CR><LF>")
+
+			var data strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+
+			s.mu.Lock()
+			s.data = data.String()
+			s.mu.Unlock()
+			reply("250 OK")
+			s.received <- struct{}{}
+		case command == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestSendEmail(t *testing.T) {
+	var (
+		server = newSmtpStandIn(t)
+		o      = New(&Config{SmtpHost: "127.0.0.1", SmtpPort: server.port(), SmtpFrom: "Shop <shop@example.com>"})
+	)
+
+	err := o.SendEmail(Email{
+		To:      []string{"Ann <ann@example.com>"},
+		Cc:      []string{"bob@example.com"},
+		Bcc:     []string{"audit@example.com"},
+		ReplyTo: "support@example.com",
+		Subject: "Order {{.Id}}",
+		Text:    "Order {{.Id}} is ready",
+		Attachments: []EmailAttachment{
+			{Filename: "order.txt", Data: []byte("order 42")},
+		},
+		TemplateData: map[string]interface{}{"Id": 42},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-server.received
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	if server.from != "<shop@example.com>" {
+		t.Errorf("MAIL FROM is %s, want bare sender address", server.from)
+	}
+
+	if got := strings.Join(server.rcpt, ","); got != "<ann@example.com>,<bob@example.com>,<audit@example.com>" {
+		t.Errorf("RCPT TO is %s", got)
+	}
+
+	for _, want := range []string{"From: Shop <shop@example.com>\r\n", "To: Ann <ann@example.com>\r\n", "Cc: bob@example.com\r\n", "Subject: Order 42\r\n", "Order 42 is ready", "filename=order.txt"} {
+		if !strings.Contains(server.data, want) {
+			t.Errorf("message has no %q", want)
+		}
+	}
+
+	if strings.Contains(server.data, "audit@example.com") {
+		t.Error("Bcc recipient is written to the message")
+	}
+}
+
+func TestSendEmailHeaderInjection(t *testing.T) {
+	var (
+		server = newSmtpStandIn(t)
+		o      = New(&Config{SmtpHost: "127.0.0.1", SmtpPort: server.port(), SmtpFrom: "shop@example.com"})
+	)
+
+	var emails = []Email{
+		{To: []string{"ann@example.com\r\nBcc: eve@example.com"}, Text: "hi"},
+		{To: []string{"ann@example.com"}, ReplyTo: "support@example.com\r\nBcc: eve@example.com", Text: "hi"},
+	}
+
+	for _, email := range emails {
+		if err := o.SendEmail(email); err == nil {
+			t.Errorf("email with line break in address was sent: %+v", email)
+		}
+	}
+
+	select {
+	case <-server.received:
+		t.Error("stand-in received message with injected header")
+	default:
+	}
+}
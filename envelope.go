@@ -0,0 +1,251 @@
+package ucodesdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+/*
+Envelope is response of any u-code object API call with objects decoded into T,
+usually map[string]interface{} or a struct with json tags of the table fields.
+It hides different nesting of the responses:
+
+	{"data": {"data": {"data": {...}}}}                  create
+	{"data": {"data": {"response": {...}}}}              get single
+	{"data": {"data": {"count": 1, "response": [...]}}}  get list
+	{"data": {"data": {"data": [...]}}}                  aggregation
+	{"data": {"data": {"objects": [...]}}}               multiple update
+	{"data": {"table_slug": "...", "data": {...}}}       update
+*/
+type Envelope[T any] struct {
+	status      string
+	description string
+	objects     []T
+	count       int
+	hasCount    bool
+	raw         []byte
+}
+
+// Object returns the object of single object response or the first object of list
+func (e Envelope[T]) Object() T {
+	var object T
+	if len(e.objects) > 0 {
+		object = e.objects[0]
+	}
+	return object
+}
+
+// Objects returns objects of list response, single object response gives one element
+func (e Envelope[T]) Objects() []T {
+	return e.objects
+}
+
+// Count returns count sent by the API, number of objects when it was not sent
+func (e Envelope[T]) Count() int {
+	if e.hasCount {
+		return e.count
+	}
+	return len(e.objects)
+}
+
+func (e Envelope[T]) Status() string {
+	return e.status
+}
+
+func (e Envelope[T]) Description() string {
+	return e.description
+}
+
+// Raw returns response body as received, adapted legacy responses return it marshalled back
+func (e Envelope[T]) Raw() []byte {
+	return e.raw
+}
+
+// ParseEnvelope decodes response body of u-code object API
+func ParseEnvelope[T any](raw []byte) (Envelope[T], error) {
+	return parseEnvelope[T](raw, json.Unmarshal)
+}
+
+func parseEnvelope[T any](raw []byte, unmarshal func(data []byte, v interface{}) error) (Envelope[T], error) {
+	var (
+		envelope = Envelope[T]{raw: raw}
+		outer    struct {
+			Status      string          `json:"status"`
+			Description string          `json:"description"`
+			Data        json.RawMessage `json:"data"`
+		}
+	)
+
+	if err := unmarshal(raw, &outer); err != nil {
+		return envelope, fmt.Errorf("error unmarshalling response: %v", err)
+	}
+	envelope.status, envelope.description = outer.Status, outer.Description
+
+	var data map[string]json.RawMessage
+	if err := unmarshalObject(outer.Data, &data); err != nil {
+		return envelope, fmt.Errorf("error unmarshalling response data: %v", err)
+	}
+
+	// update response keeps the object right under data
+	if _, ok := data["table_slug"]; ok {
+		return envelope, envelope.decode(data["data"], unmarshal)
+	}
+
+	var inner map[string]json.RawMessage
+	if err := unmarshalObject(data["data"], &inner); err != nil {
+		return envelope, fmt.Errorf("error unmarshalling response data: %v", err)
+	}
+
+	if count, ok := inner["count"]; ok {
+		envelope.hasCount = json.Unmarshal(count, &envelope.count) == nil
+	}
+
+	for _, key := range []string{"response", "objects", "data"} {
+		if value, ok := inner[key]; ok {
+			return envelope, envelope.decode(value, unmarshal)
+		}
+	}
+
+	return envelope, nil
+}
+
+// decode fills objects from JSON object or array
+func (e *Envelope[T]) decode(value json.RawMessage, unmarshal func(data []byte, v interface{}) error) error {
+	value = bytes.TrimSpace(value)
+	if len(value) == 0 || bytes.Equal(value, []byte("null")) {
+		return nil
+	}
+
+	if value[0] == '[' {
+		if err := unmarshal(value, &e.objects); err != nil {
+			return fmt.Errorf("error unmarshalling objects: %v", err)
+		}
+		return nil
+	}
+
+	var object T
+	if err := unmarshal(value, &object); err != nil {
+		return fmt.Errorf("error unmarshalling object: %v", err)
+	}
+
+	e.objects = []T{object}
+	return nil
+}
+
+// unmarshalObject decodes JSON object, null and missing values give empty map
+func unmarshalObject(value json.RawMessage, target *map[string]json.RawMessage) error {
+	if len(bytes.TrimSpace(value)) == 0 {
+		return nil
+	}
+	return json.Unmarshal(value, target)
+}
+
+// GetSingleAs works like GetSingle with the object decoded into T
+func GetSingleAs[T any](o *ObjectFunction, arg *Argument, opts ...CallOption) (Envelope[T], error) {
+	arg, call := o.prepare(arg, opts)
+	return callEnvelope[T](o, call, arg, o.getSingleRequest(arg))
+}
+
+// GetListAs works like GetList with objects decoded into T
+func GetListAs[T any](o *ObjectFunction, arg *Argument, opts ...CallOption) (Envelope[T], error) {
+	arg, call := o.prepare(arg, opts)
+	return callEnvelope[T](o, call, arg, o.getListRequest(arg))
+}
+
+// CreateObjectAs works like CreateObject with created object decoded into T
+func CreateObjectAs[T any](o *ObjectFunction, arg *Argument, opts ...CallOption) (Envelope[T], error) {
+	arg, call := o.prepare(arg, opts)
+	return callEnvelope[T](o, call, arg, o.createObjectRequest(arg))
+}
+
+// UpdateObjectAs works like UpdateObject with updated object decoded into T
+func UpdateObjectAs[T any](o *ObjectFunction, arg *Argument, opts ...CallOption) (Envelope[T], error) {
+	arg, call := o.prepare(arg, opts)
+	return callEnvelope[T](o, call, arg, o.updateObjectRequest(arg))
+}
+
+func callEnvelope[T any](o *ObjectFunction, call *callOptions, arg *Argument, req apiRequest) (Envelope[T], error) {
+	respByte, err := o.doRequest(call, req)
+	if err != nil {
+		return Envelope[T]{raw: respByte}, err
+	}
+
+	if len(call.expand) == 0 {
+		return parseEnvelope[T](respByte, o.unmarshal)
+	}
+
+	// related objects are stitched into maps, then objects are decoded into T
+	rows, err := parseEnvelope[map[string]interface{}](respByte, o.unmarshal)
+	if err != nil {
+		return Envelope[T]{raw: respByte}, err
+	}
+
+	if err = o.expand(call, arg, rows.objects); err != nil {
+		return Envelope[T]{raw: respByte}, err
+	}
+
+	var envelope = Envelope[T]{status: rows.status, description: rows.description, count: rows.count, hasCount: rows.hasCount, raw: respByte}
+
+	objects, err := o.marshal(rows.objects)
+	if err != nil {
+		return envelope, fmt.Errorf("error marshalling expanded objects: %v", err)
+	}
+
+	if err = o.unmarshal(objects, &envelope.objects); err != nil {
+		return envelope, fmt.Errorf("error unmarshalling expanded objects: %v", err)
+	}
+
+	return envelope, nil
+}
+
+// adaptEnvelope builds envelope of legacy response type
+func adaptEnvelope(legacy interface{}, status, description string, objects []map[string]interface{}, single bool) Envelope[map[string]interface{}] {
+	raw, _ := json.Marshal(legacy)
+
+	var envelope = Envelope[map[string]interface{}]{status: status, description: description, raw: raw}
+
+	// empty legacy single object response has nil map, it is not an object
+	if !single || objects[0] != nil {
+		envelope.objects = objects
+	}
+
+	return envelope
+}
+
+// Envelope adapts legacy response to Envelope
+func (r Datas) Envelope() Envelope[map[string]interface{}] {
+	return adaptEnvelope(r, "", "", []map[string]interface{}{r.Data.Data.Data}, true)
+}
+
+// Envelope adapts legacy response to Envelope
+func (r ClientApiResponse) Envelope() Envelope[map[string]interface{}] {
+	return adaptEnvelope(r, "", "", []map[string]interface{}{r.Data.Data.Response}, true)
+}
+
+// Envelope adapts legacy response to Envelope
+func (r GetListClientApiResponse) Envelope() Envelope[map[string]interface{}] {
+	var envelope = adaptEnvelope(r, "", "", r.Data.Data.Response, false)
+	envelope.count, envelope.hasCount = r.Data.Data.Count, true
+	return envelope
+}
+
+// Envelope adapts legacy response to Envelope
+func (r GetListAggregationClientApiResponse) Envelope() Envelope[map[string]interface{}] {
+	return adaptEnvelope(r, "", "", r.Data.Data.Data, false)
+}
+
+// Envelope adapts legacy response to Envelope
+func (r ClientApiUpdateResponse) Envelope() Envelope[map[string]interface{}] {
+	return adaptEnvelope(r, r.Status, r.Description, []map[string]interface{}{r.Data.Data}, true)
+}
+
+// Envelope adapts legacy response to Envelope
+func (r ClientApiMultipleUpdateResponse) Envelope() Envelope[map[string]interface{}] {
+	return adaptEnvelope(r, r.Status, r.Description, r.Data.Data.Objects, false)
+}
+
+// Envelope adapts legacy response to Envelope
+func (r ClientApiMultipleUpsertResponse) Envelope() Envelope[map[string]interface{}] {
+	return adaptEnvelope(r, r.Status, r.Description, []map[string]interface{}{r.Data.Data}, true)
+}
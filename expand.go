@@ -0,0 +1,320 @@
+package ucodesdk
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+const (
+	// defaultExpandDepth limits nesting of expanded paths unless changed with WithCallExpandDepth
+	defaultExpandDepth = 3
+	// expandBatchSize is number of ids in one lookup and objects in one page of related objects
+	expandBatchSize = 500
+)
+
+/*
+Expansion describes related objects loaded into rows by WithCallExpandRelations.
+Path is field name, nested with dots: "items.product_id" expands product_id of expanded items.
+Objects of Table are matched either by guid kept in the field (ForeignKey is empty)
+or by ForeignKey field of Table referencing guid of the row. They are stored in the row under As.
+*/
+type Expansion struct {
+	Path       string
+	Table      string
+	ForeignKey string
+	As         string
+}
+
+/*
+WithCallExpand loads related objects into rows returned by GetList, GetListSlim, GetSingle,
+GetSingleSlim and typed *As calls. Relation of a path field follows u-code naming:
+
+	customer_id   object of customer table, stored in customer_id_data
+	product_ids   objects of product table, stored in product_ids_data
+	items         objects of items table with <parent table>_id of the row, stored in items
+
+Related objects of all rows are loaded with one GetList with guid IN (...) filter per path,
+instead of a call per row. Typed structs receive them through fields with the same json tags.
+*/
+func WithCallExpand(paths ...string) CallOption {
+	return func(c *callOptions) {
+		for _, path := range paths {
+			c.expand = append(c.expand, Expansion{Path: path})
+		}
+	}
+}
+
+// WithCallExpandRelations works like WithCallExpand for relations that do not follow u-code naming
+func WithCallExpandRelations(expansions ...Expansion) CallOption {
+	return func(c *callOptions) {
+		c.expand = append(c.expand, expansions...)
+	}
+}
+
+// WithCallExpandDepth sets how deep expanded paths may be nested, 3 by default
+func WithCallExpandDepth(depth int) CallOption {
+	return func(c *callOptions) {
+		c.expandDepth = depth
+	}
+}
+
+// expandNode is expansion of one path with expansions nested into it
+type expandNode struct {
+	Expansion
+	field    string
+	children []*expandNode
+}
+
+// expandTree resolves expansions of the call into tree, missing parent paths are added by naming
+func expandTree(call *callOptions, table string) ([]*expandNode, error) {
+	var (
+		depth = call.expandDepth
+		root  = &expandNode{Expansion: Expansion{Table: table}}
+		nodes = map[string]*expandNode{"": root}
+	)
+
+	if depth <= 0 {
+		depth = defaultExpandDepth
+	}
+
+	var add func(expansion Expansion) (*expandNode, error)
+	add = func(expansion Expansion) (*expandNode, error) {
+		var path = strings.Trim(expansion.Path, ".")
+		if path == "" {
+			return nil, fmt.Errorf("expand path is empty")
+		}
+
+		if strings.Count(path, ".")+1 > depth {
+			return nil, fmt.Errorf("expand path %s is deeper than %d", path, depth)
+		}
+
+		var parentPath, field = "", path
+		if i := strings.LastIndex(path, "."); i >= 0 {
+			parentPath, field = path[:i], path[i+1:]
+		}
+
+		parent, ok := nodes[parentPath]
+		if !ok {
+			var err error
+			if parent, err = add(Expansion{Path: parentPath}); err != nil {
+				return nil, err
+			}
+		}
+
+		node, ok := nodes[path]
+		if !ok {
+			node = &expandNode{field: field}
+			nodes[path] = node
+			parent.children = append(parent.children, node)
+		}
+
+		// explicit expansion overrides the one added by naming
+		if node.Table == "" || expansion != (Expansion{Path: expansion.Path}) {
+			node.Expansion = expansion.resolve(field, parent.Table)
+		}
+
+		return node, nil
+	}
+
+	for _, expansion := range call.expand {
+		if _, err := add(expansion); err != nil {
+			return nil, err
+		}
+	}
+
+	return root.children, nil
+}
+
+// resolve fills empty fields of expansion by u-code naming
+func (e Expansion) resolve(field, parentTable string) Expansion {
+	switch {
+	case e.Table != "":
+	case strings.HasSuffix(field, "_ids"):
+		e.Table = strings.TrimSuffix(field, "_ids")
+	case strings.HasSuffix(field, "_id"):
+		e.Table = strings.TrimSuffix(field, "_id")
+	default:
+		e.Table, e.ForeignKey = field, firstNonEmpty(e.ForeignKey, parentTable+"_id")
+	}
+
+	if e.As == "" {
+		e.As = field
+		if e.ForeignKey == "" {
+			e.As = field + "_data"
+		}
+	}
+
+	return e
+}
+
+// expand loads related objects of the call expansions into rows of table
+func (o *ObjectFunction) expand(call *callOptions, arg *Argument, rows []map[string]interface{}) error {
+	if len(call.expand) == 0 || len(rows) == 0 {
+		return nil
+	}
+
+	nodes, err := expandTree(call, arg.TableSlug)
+	if err != nil {
+		return err
+	}
+
+	return o.expandRows(call, arg, rows, nodes)
+}
+
+func (o *ObjectFunction) expandRows(call *callOptions, arg *Argument, rows []map[string]interface{}, nodes []*expandNode) error {
+	for _, node := range nodes {
+		var (
+			related []map[string]interface{}
+			err     error
+		)
+
+		if node.ForeignKey == "" {
+			related, err = o.expandReferenced(call, arg, rows, node)
+		} else {
+			related, err = o.expandReferencing(call, arg, rows, node)
+		}
+		if err != nil {
+			return fmt.Errorf("error expanding %s: %v", node.Path, err)
+		}
+
+		if len(node.children) > 0 && len(related) > 0 {
+			if err := o.expandRows(call, arg, related, node.children); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// expandReferenced stores objects with guids kept in node field of rows
+func (o *ObjectFunction) expandReferenced(call *callOptions, arg *Argument, rows []map[string]interface{}, node *expandNode) ([]map[string]interface{}, error) {
+	var ids []string
+	for _, row := range rows {
+		refs, _ := expandIds(row[node.field])
+		ids = append(ids, refs...)
+	}
+
+	related, err := o.expandLookup(call, arg, node.Table, "guid", ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var byId = make(map[string]map[string]interface{}, len(related))
+	for _, object := range related {
+		byId[cast.ToString(object["guid"])] = object
+	}
+
+	for _, row := range rows {
+		refs, many := expandIds(row[node.field])
+		if !many {
+			if len(refs) > 0 && byId[refs[0]] != nil {
+				row[node.As] = byId[refs[0]]
+			}
+			continue
+		}
+
+		var objects = make([]map[string]interface{}, 0, len(refs))
+		for _, id := range refs {
+			if object, ok := byId[id]; ok {
+				objects = append(objects, object)
+			}
+		}
+		row[node.As] = objects
+	}
+
+	return related, nil
+}
+
+// expandReferencing stores objects referencing rows with node foreign key
+func (o *ObjectFunction) expandReferencing(call *callOptions, arg *Argument, rows []map[string]interface{}, node *expandNode) ([]map[string]interface{}, error) {
+	var ids = make([]string, 0, len(rows))
+	for _, row := range rows {
+		if id := cast.ToString(row["guid"]); id != "" {
+			ids = append(ids, id)
+		}
+	}
+
+	related, err := o.expandLookup(call, arg, node.Table, node.ForeignKey, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var byParent = map[string][]map[string]interface{}{}
+	for _, object := range related {
+		parent := cast.ToString(object[node.ForeignKey])
+		byParent[parent] = append(byParent[parent], object)
+	}
+
+	for _, row := range rows {
+		var objects = byParent[cast.ToString(row["guid"])]
+		if objects == nil {
+			objects = []map[string]interface{}{}
+		}
+		row[node.As] = objects
+	}
+
+	return related, nil
+}
+
+// expandLookup loads objects of table with field IN values, in batches and pages of expandBatchSize
+func (o *ObjectFunction) expandLookup(call *callOptions, arg *Argument, table, field string, values []string) ([]map[string]interface{}, error) {
+	// difference with nothing drops duplicates
+	values = differenceStrings(values, []string{""})
+
+	var opts = []CallOption{WithCallContext(call.ctx), WithCallAppId(call.appId), WithCallRequestId(call.requestId), WithCallHeaders(call.headers)}
+	if call.skipCache {
+		opts = append(opts, WithCallSkipCache())
+	}
+
+	var objects []map[string]interface{}
+	for start := 0; start < len(values); start += expandBatchSize {
+		var batch = values[start:min(start+expandBatchSize, len(values))]
+
+		for page, loaded := 1, 0; ; page++ {
+			list, _, err := o.GetList(&Argument{
+				TableSlug:   table,
+				DisableFaas: arg.DisableFaas,
+				Request:     Request{Data: map[string]interface{}{field: batch, "page": page, "limit": expandBatchSize}},
+			}, opts...)
+			if err != nil {
+				return nil, err
+			}
+
+			var response = list.Data.Data.Response
+			objects, loaded = append(objects, response...), loaded+len(response)
+
+			// count is compared only when sent, short page ends the list otherwise
+			if len(response) < expandBatchSize || (list.Data.Data.Count > 0 && loaded >= list.Data.Data.Count) {
+				break
+			}
+		}
+	}
+
+	return objects, nil
+}
+
+// expandIds returns guids kept in field value, many tells the value is a list
+func expandIds(value interface{}) (ids []string, many bool) {
+	switch value := value.(type) {
+	case string:
+		if value != "" {
+			ids = []string{value}
+		}
+		return ids, false
+	case []interface{}, []string:
+		return cast.ToStringSlice(value), true
+	default:
+		return nil, false
+	}
+}
+
+// singleRows returns single object response as rows, empty response gives no rows
+func singleRows(object map[string]interface{}) []map[string]interface{} {
+	if object == nil {
+		return nil
+	}
+	return []map[string]interface{}{object}
+}
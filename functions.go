@@ -1,20 +1,17 @@
 package ucodesdk
 
 import (
-	"context"
-	"encoding/json"
+	"bytes"
 	"fmt"
 	"net/http"
-	httpUrl "net/url"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	firebase "firebase.google.com/go/v4"
 	"firebase.google.com/go/v4/messaging"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
-	"google.golang.org/api/option"
 
 	"github.com/spf13/cast"
 	tgbotapiK "gopkg.in/telegram-bot-api.v4"
@@ -23,108 +20,155 @@ import (
 type ObjectFunction struct {
 	Cfg    *Config
 	Logger *FaasLogger
+
+	httpClient    *http.Client
+	retryAttempts int
+	retryBackoff  time.Duration
+	timeout       time.Duration
+	userAgent     string
+	headers       map[string]string
+
+	codec                 Codec
+	debugHook             func(info RequestInfo)
+	gzipThreshold         int
+	noResponseCompression bool
+
+	cache      CacheBackend
+	cacheTTL   time.Duration
+	noCoalesce bool
+	inflight   coalescer
+
+	limit         *limiter
+	classLimits   map[EndpointClass]*limiter
+	throttleMu    sync.Mutex
+	throttleStats map[EndpointClass]ThrottleStats
+
+	breakerCfg *BreakerConfig
+	breakersMu sync.Mutex
+	breakers   map[string]*circuitBreaker
+
+	idempotencyMu     sync.Mutex
+	idempotencyStore  CacheBackend
+	idempotencyWindow time.Duration
+
+	fcmMu     sync.Mutex
+	fcmClient *messaging.Client
+
+	dispatcherMu sync.Mutex
+	dispatcher   *Dispatcher
+
+	secretsMu sync.Mutex
+	secrets   map[string]bool
 }
 
-func New(cfg *Config) *ObjectFunction {
-	return &ObjectFunction{
-		Cfg:    cfg,
-		Logger: NewLoggerFunction(cfg.FunctionName),
+func New(cfg *Config, opts ...Option) *ObjectFunction {
+	o := &ObjectFunction{
+		Cfg:          cfg,
+		Logger:       NewLoggerFunction(cfg.FunctionName),
+		retryBackoff: 200 * time.Millisecond,
+	}
+
+	for _, opt := range opts {
+		opt(o)
 	}
+
+	return o
 }
 
-func (o *ObjectFunction) CreateObject(arg *Argument) (Datas, Response, error) {
-	var (
-		response      = Response{Status: "done"}
-		createdObject = Datas{}
-		url           = fmt.Sprintf("%s/v1/object/%s?from-ofs=%t&block_builder=%t&blocked_login_table=%t", o.Cfg.BaseURL, arg.TableSlug, arg.DisableFaas, arg.BlockBuilder, arg.BlockedLoginTable)
-	)
+func (o *ObjectFunction) CreateObject(arg *Argument, opts ...CallOption) (Datas, Response, error) {
+	arg, call := o.prepare(arg, opts)
 
-	var appId = o.Cfg.AppId
-	if arg.AppId != "" {
-		appId = arg.AppId
-	}
+	var createdObject = Datas{}
 
-	createObjectResponseInByte, err := DoRequest(url, "POST", arg.Request, appId)
+	createObjectResponseInByte, err := o.doRequest(call, o.createObjectRequest(arg))
 	if err != nil {
-		response.Data = map[string]any{"description": string(createObjectResponseInByte), "message": "Can't send request", "error": err.Error()}
-		response.Status = "error"
-		return Datas{}, response, err
+		return Datas{}, o.result(call, err, map[string]any{"description": string(createObjectResponseInByte), "message": "Can't send request", "error": err.Error()}), err
 	}
 
-	err = json.Unmarshal(createObjectResponseInByte, &createdObject)
+	err = o.unmarshal(createObjectResponseInByte, &createdObject)
 	if err != nil {
-		response.Data = map[string]any{"description": string(createObjectResponseInByte), "message": "Error while unmarshalling create object", "error": err.Error()}
-		response.Status = "error"
-		return Datas{}, response, err
+		return Datas{}, o.result(call, err, map[string]any{"description": string(createObjectResponseInByte), "message": "Error while unmarshalling create object", "error": err.Error()}), err
 	}
 
-	return createdObject, response, nil
+	return createdObject, o.result(call, nil, nil), nil
 }
 
-func (o *ObjectFunction) UpdateObject(arg *Argument) (ClientApiUpdateResponse, Response, error) {
-	var (
-		response     = Response{Status: "done"}
-		updateObject = ClientApiUpdateResponse{}
-		url          = fmt.Sprintf("%s/v1/object/%s?from-ofs=%t&block_builder=%t", o.Cfg.BaseURL, arg.TableSlug, arg.DisableFaas, arg.BlockBuilder)
-	)
+func (o *ObjectFunction) createObjectRequest(arg *Argument) apiRequest {
+	var url = fmt.Sprintf("%s/v1/object/%s?from-ofs=%t&block_builder=%t&blocked_login_table=%t", o.Cfg.BaseURL, arg.TableSlug, arg.DisableFaas, arg.BlockBuilder, arg.BlockedLoginTable)
+	return apiRequest{method: "POST", url: url, body: arg.Request, kind: requestWrite, tables: []string{arg.TableSlug}}
+}
 
-	var appId = o.Cfg.AppId
-	if arg.AppId != "" {
-		appId = arg.AppId
-	}
+func (o *ObjectFunction) UpdateObject(arg *Argument, opts ...CallOption) (ClientApiUpdateResponse, Response, error) {
+	arg, call := o.prepare(arg, opts)
+
+	var updateObject = ClientApiUpdateResponse{}
 
-	updateObjectResponseInByte, err := DoRequest(url, "PUT", arg.Request, appId)
+	updateObjectResponseInByte, err := o.doRequest(call, o.updateObjectRequest(arg))
 	if err != nil {
-		response.Data = map[string]any{"description": string(updateObjectResponseInByte), "message": "Error while updating object", "error": err.Error()}
-		response.Status = "error"
-		return ClientApiUpdateResponse{}, response, err
+		return ClientApiUpdateResponse{}, o.result(call, err, map[string]any{"description": string(updateObjectResponseInByte), "message": "Error while updating object", "error": err.Error()}), err
 	}
 
-	err = json.Unmarshal(updateObjectResponseInByte, &updateObject)
+	err = o.unmarshal(updateObjectResponseInByte, &updateObject)
 	if err != nil {
-		response.Data = map[string]any{"description": string(updateObjectResponseInByte), "message": "Error while unmarshalling update object", "error": err.Error()}
-		response.Status = "error"
-		return ClientApiUpdateResponse{}, response, err
+		return ClientApiUpdateResponse{}, o.result(call, err, map[string]any{"description": string(updateObjectResponseInByte), "message": "Error while unmarshalling update object", "error": err.Error()}), err
 	}
 
-	return updateObject, response, nil
+	return updateObject, o.result(call, nil, nil), nil
 }
 
-func (o *ObjectFunction) MultipleUpdate(arg *Argument) (ClientApiMultipleUpdateResponse, Response, error) {
+func (o *ObjectFunction) updateObjectRequest(arg *Argument) apiRequest {
+	var url = fmt.Sprintf("%s/v1/object/%s?from-ofs=%t&block_builder=%t", o.Cfg.BaseURL, arg.TableSlug, arg.DisableFaas, arg.BlockBuilder)
+	return apiRequest{method: "PUT", url: url, body: arg.Request, kind: requestWrite, tables: []string{arg.TableSlug}}
+}
+
+func (o *ObjectFunction) MultipleUpdate(arg *Argument, opts ...CallOption) (ClientApiMultipleUpdateResponse, Response, error) {
+	arg, call := o.prepare(arg, opts)
+
 	var (
-		response             = Response{Status: "done"}
 		multipleUpdateObject = ClientApiMultipleUpdateResponse{}
 		url                  = fmt.Sprintf("%s/v1/object/multiple-update/%s?from-ofs=%t&block_builder=%t", o.Cfg.BaseURL, arg.TableSlug, arg.DisableFaas, arg.BlockBuilder)
 	)
 
-	var appId = o.Cfg.AppId
-	if arg.AppId != "" {
-		appId = arg.AppId
+	multipleUpdateObjectsResponseInByte, err := o.doRequest(call, apiRequest{method: "PUT", url: url, body: arg.Request, kind: requestWrite, tables: []string{arg.TableSlug}})
+	if err != nil {
+		return ClientApiMultipleUpdateResponse{}, o.result(call, err, map[string]any{"description": string(multipleUpdateObjectsResponseInByte), "message": "Error while multiple updating objects", "error": err.Error()}), err
+	}
+
+	err = o.unmarshal(multipleUpdateObjectsResponseInByte, &multipleUpdateObject)
+	if err != nil {
+		return ClientApiMultipleUpdateResponse{}, o.result(call, err, map[string]any{"description": string(multipleUpdateObjectsResponseInByte), "message": "Error while unmarshalling multiple update objects", "error": err.Error()}), err
 	}
 
-	multipleUpdateObjectsResponseInByte, err := DoRequest(url, "PUT", arg.Request, appId)
+	return multipleUpdateObject, o.result(call, nil, nil), nil
+}
+
+func (o *ObjectFunction) GetList(arg *Argument, opts ...CallOption) (GetListClientApiResponse, Response, error) {
+	arg, call := o.prepare(arg, opts)
+
+	var getListObject GetListClientApiResponse
+
+	getListResponseInByte, err := o.doRequest(call, o.getListRequest(arg))
 	if err != nil {
-		response.Data = map[string]any{"description": string(multipleUpdateObjectsResponseInByte), "message": "Error while multiple updating objects", "error": err.Error()}
-		response.Status = "error"
-		return ClientApiMultipleUpdateResponse{}, response, err
+		return GetListClientApiResponse{}, o.result(call, err, map[string]any{"description": string(getListResponseInByte), "message": "Can't send request", "error": err.Error()}), err
 	}
 
-	err = json.Unmarshal(multipleUpdateObjectsResponseInByte, &multipleUpdateObject)
+	err = o.unmarshal(getListResponseInByte, &getListObject)
 	if err != nil {
-		response.Data = map[string]any{"description": string(multipleUpdateObjectsResponseInByte), "message": "Error while unmarshalling multiple update objects", "error": err.Error()}
-		response.Status = "error"
-		return ClientApiMultipleUpdateResponse{}, response, err
+		return GetListClientApiResponse{}, o.result(call, err, map[string]any{"description": string(getListResponseInByte), "message": "Error while unmarshalling get list object", "error": err.Error()}), err
 	}
 
-	return multipleUpdateObject, response, nil
+	err = o.expand(call, arg, getListObject.Data.Data.Response)
+	if err != nil {
+		return GetListClientApiResponse{}, o.result(call, err, map[string]any{"message": "Error while expanding relations", "error": err.Error()}), err
+	}
+
+	return getListObject, o.result(call, nil, nil), nil
 }
 
-func (o *ObjectFunction) GetList(arg *Argument) (GetListClientApiResponse, Response, error) {
+func (o *ObjectFunction) getListRequest(arg *Argument) apiRequest {
 	var (
-		response      Response
-		getListObject GetListClientApiResponse
-		url           = fmt.Sprintf("%s/v2/object/get-list/%s?from-ofs=%t", o.Cfg.BaseURL, arg.TableSlug, arg.DisableFaas)
-		page, limit   int
+		url         = fmt.Sprintf("%s/v2/object/get-list/%s?from-ofs=%t", o.Cfg.BaseURL, arg.TableSlug, arg.DisableFaas)
+		page, limit int
 	)
 
 	if _, ok := arg.Request.Data["page"].(int); ok {
@@ -146,79 +190,71 @@ func (o *ObjectFunction) GetList(arg *Argument) (GetListClientApiResponse, Respo
 	arg.Request.Data["offset"] = (page - 1) * limit
 	arg.Request.Data["limit"] = limit
 
-	var appId = o.Cfg.AppId
-	if arg.AppId != "" {
-		appId = arg.AppId
+	return apiRequest{method: "POST", url: url, body: arg.Request, kind: requestRead, tables: []string{arg.TableSlug}}
+}
+func (o *ObjectFunction) GetListSlim(arg *Argument, opts ...CallOption) (GetListClientApiResponse, Response, error) {
+	arg, call := o.prepare(arg, opts)
+
+	var listSlim GetListClientApiResponse
+
+	req, err := o.getListSlimRequest(arg)
+	if err != nil {
+		return GetListClientApiResponse{}, o.result(call, err, map[string]any{"message": "Error while marshalling request getting list slim object", "error": err.Error()}), err
+	}
+
+	getListResponseInByte, err := o.doRequest(call, req)
+	if err != nil {
+		return GetListClientApiResponse{}, o.result(call, err, map[string]any{"description": string(getListResponseInByte), "message": "Can't sent request", "error": err.Error()}), err
 	}
 
-	getListResponseInByte, err := DoRequest(url, "POST", arg.Request, appId)
+	err = o.unmarshal(getListResponseInByte, &listSlim)
 	if err != nil {
-		response.Data = map[string]any{"description": string(getListResponseInByte), "message": "Can't send request", "error": err.Error()}
-		response.Status = "error"
-		return GetListClientApiResponse{}, response, err
+		return GetListClientApiResponse{}, o.result(call, err, map[string]any{"description": string(getListResponseInByte), "message": "Error while unmarshalling get list object", "error": err.Error()}), err
 	}
 
-	err = json.Unmarshal(getListResponseInByte, &getListObject)
+	err = o.expand(call, arg, listSlim.Data.Data.Response)
 	if err != nil {
-		response.Data = map[string]any{"description": string(getListResponseInByte), "message": "Error while unmarshalling get list object", "error": err.Error()}
-		response.Status = "error"
-		return GetListClientApiResponse{}, response, err
+		return GetListClientApiResponse{}, o.result(call, err, map[string]any{"message": "Error while expanding relations", "error": err.Error()}), err
 	}
 
-	return getListObject, response, nil
+	return listSlim, o.result(call, nil, nil), nil
 }
 
-func (o *ObjectFunction) GetListSlim(arg *Argument) (GetListClientApiResponse, Response, error) {
-	var (
-		response    Response
-		listSlim    GetListClientApiResponse
-		url         = fmt.Sprintf("%s/v2/object-slim/get-list/%s?from-ofs=%t&block_cached=%t", o.Cfg.BaseURL, arg.TableSlug, arg.DisableFaas, arg.BlockCached)
-		page, limit int
-	)
+// getListSlimRequest sends Data in query, it is marshalled once and escaped straight into the url
+func (o *ObjectFunction) getListSlimRequest(arg *Argument) (apiRequest, error) {
+	var page, limit int
 
-	reqObject, err := json.Marshal(arg.Request.Data)
+	reqObject, err := o.marshal(arg.Request.Data)
 	if err != nil {
-		response.Data = map[string]any{"message": "Error while marshalling request getting list slim object", "error": err.Error()}
-		response.Status = "error"
-		return GetListClientApiResponse{}, response, err
+		return apiRequest{}, err
 	}
 
+	var url = bufferPool.Get().(*bytes.Buffer)
+	defer putBuffer(url)
+
+	url.Grow(len(o.Cfg.BaseURL) + len(arg.TableSlug) + len(reqObject)*3 + 96)
+	fmt.Fprintf(url, "%s/v2/object-slim/get-list/%s?from-ofs=%t&block_cached=%t", o.Cfg.BaseURL, arg.TableSlug, arg.DisableFaas, arg.BlockCached)
+
 	if _, ok := arg.Request.Data["limit"]; ok {
 		limit = arg.Request.Data["limit"].(int)
-		url = fmt.Sprintf("%s&limit=%d", url, limit)
+		fmt.Fprintf(url, "&limit=%d", limit)
 	}
 
 	if _, ok := arg.Request.Data["page"].(int); ok {
 		page = arg.Request.Data["page"].(int)
-		url = fmt.Sprintf("%s&offset=%d", url, (page-1)*limit)
-	}
-
-	url = fmt.Sprintf("%s&data=%s", url, httpUrl.QueryEscape(string(reqObject)))
-	var appId = o.Cfg.AppId
-	if arg.AppId != "" {
-		appId = arg.AppId
-	}
-
-	getListResponseInByte, err := DoRequest(url, "GET", nil, appId)
-	if err != nil {
-		response.Data = map[string]any{"description": string(getListResponseInByte), "message": "Can't sent request", "error": err.Error()}
-		response.Status = "error"
-		return GetListClientApiResponse{}, response, err
+		fmt.Fprintf(url, "&offset=%d", (page-1)*limit)
 	}
 
-	err = json.Unmarshal(getListResponseInByte, &listSlim)
-	if err != nil {
-		response.Data = map[string]any{"description": string(getListResponseInByte), "message": "Error while unmarshalling get list object", "error": err.Error()}
-		response.Status = "error"
-		return GetListClientApiResponse{}, response, err
-	}
+	url.WriteString("&data=")
+	queryEscape(url, reqObject)
 
-	return listSlim, response, nil
+	return apiRequest{method: "GET", url: url.String(), body: nil, kind: requestRead, tables: []string{arg.TableSlug}}, nil
 }
 
-func (o *ObjectFunction) GetListAggregate(arg *Argument) (GetListClientApiResponse, Response, error) {
+func (o *ObjectFunction) GetListAggregate(arg *Argument, opts ...CallOption) (GetListClientApiResponse, Response, error) {
+	arg, call := o.prepare(arg, opts)
+
 	var (
-		response         Response
 		getListAggregate GetListClientApiResponse
 		url              = fmt.Sprintf("%s/v1/object/get-list-aggregate/%s?from-ofs=%t&block_cached=%t", o.Cfg.BaseURL, arg.TableSlug, arg.DisableFaas, arg.BlockCached)
 		page, limit      int
@@ -234,229 +270,171 @@ func (o *ObjectFunction) GetListAggregate(arg *Argument) (GetListClientApiRespon
 		url = fmt.Sprintf("%s&offset=%d", url, (page-1)*limit)
 	}
 
-	var appId = o.Cfg.AppId
-	if arg.AppId != "" {
-		appId = arg.AppId
-	}
-
-	getListAggregateResponseInByte, err := DoRequest(url, "POST", arg.Request, appId)
+	getListAggregateResponseInByte, err := o.doRequest(call, apiRequest{method: "POST", url: url, body: arg.Request, kind: requestAggregate, tables: []string{arg.TableSlug}})
 	if err != nil {
-		response.Data = map[string]any{"description": string(getListAggregateResponseInByte), "message": "Can't sent request", "error": err.Error()}
-		response.Status = "error"
-		return GetListClientApiResponse{}, response, err
+		return GetListClientApiResponse{}, o.result(call, err, map[string]any{"description": string(getListAggregateResponseInByte), "message": "Can't sent request", "error": err.Error()}), err
 	}
 
-	err = json.Unmarshal(getListAggregateResponseInByte, &getListAggregate)
+	err = o.unmarshal(getListAggregateResponseInByte, &getListAggregate)
 	if err != nil {
-		response.Data = map[string]any{"description": string(getListAggregateResponseInByte), "message": "Error while unmarshalling get list object", "error": err.Error()}
-		response.Status = "error"
-		return GetListClientApiResponse{}, response, err
+		return GetListClientApiResponse{}, o.result(call, err, map[string]any{"description": string(getListAggregateResponseInByte), "message": "Error while unmarshalling get list object", "error": err.Error()}), err
 	}
 
-	return getListAggregate, response, nil
+	return getListAggregate, o.result(call, nil, nil), nil
 }
 
-func (o *ObjectFunction) GetSingle(arg *Argument) (ClientApiResponse, Response, error) {
-	var (
-		response  Response
-		getObject ClientApiResponse
-		url       = fmt.Sprintf("%s/v1/object/%s/%v?from-ofs=%t", o.Cfg.BaseURL, arg.TableSlug, arg.Request.Data["guid"], arg.DisableFaas)
-	)
+func (o *ObjectFunction) GetSingle(arg *Argument, opts ...CallOption) (ClientApiResponse, Response, error) {
+	arg, call := o.prepare(arg, opts)
+
+	var getObject ClientApiResponse
 
-	var appId = o.Cfg.AppId
-	if arg.AppId != "" {
-		appId = arg.AppId
+	resByte, err := o.doRequest(call, o.getSingleRequest(arg))
+	if err != nil {
+		return ClientApiResponse{}, o.result(call, err, map[string]any{"description": string(resByte), "message": "Can't sent request", "error": err.Error()}), err
 	}
 
-	resByte, err := DoRequest(url, "GET", nil, appId)
+	err = o.unmarshal(resByte, &getObject)
 	if err != nil {
-		response.Data = map[string]any{"description": string(resByte), "message": "Can't sent request", "error": err.Error()}
-		response.Status = "error"
-		return ClientApiResponse{}, response, err
+		return ClientApiResponse{}, o.result(call, err, map[string]any{"description": string(resByte), "message": "Error while unmarshalling get list object", "error": err.Error()}), err
 	}
 
-	err = json.Unmarshal(resByte, &getObject)
+	err = o.expand(call, arg, singleRows(getObject.Data.Data.Response))
 	if err != nil {
-		response.Data = map[string]any{"description": string(resByte), "message": "Error while unmarshalling get list object", "error": err.Error()}
-		response.Status = "error"
-		return ClientApiResponse{}, response, err
+		return ClientApiResponse{}, o.result(call, err, map[string]any{"message": "Error while expanding relations", "error": err.Error()}), err
 	}
 
-	return getObject, response, nil
+	return getObject, o.result(call, nil, nil), nil
+}
+
+func (o *ObjectFunction) getSingleRequest(arg *Argument) apiRequest {
+	var url = fmt.Sprintf("%s/v1/object/%s/%v?from-ofs=%t", o.Cfg.BaseURL, arg.TableSlug, arg.Request.Data["guid"], arg.DisableFaas)
+	return apiRequest{method: "GET", url: url, body: nil, kind: requestRead, tables: []string{arg.TableSlug}}
 }
 
-func (o *ObjectFunction) GetSingleSlim(arg *Argument) (ClientApiResponse, Response, error) {
+func (o *ObjectFunction) GetSingleSlim(arg *Argument, opts ...CallOption) (ClientApiResponse, Response, error) {
+	arg, call := o.prepare(arg, opts)
+
 	var (
-		response  Response
 		getObject ClientApiResponse
 		url       = fmt.Sprintf("%s/v1/object-slim/%s/%v?from-ofs=%t", o.Cfg.BaseURL, arg.TableSlug, arg.Request.Data["guid"], arg.DisableFaas)
 	)
 
-	var appId = o.Cfg.AppId
-	if arg.AppId != "" {
-		appId = arg.AppId
+	resByte, err := o.doRequest(call, apiRequest{method: "GET", url: url, body: nil, kind: requestRead, tables: []string{arg.TableSlug}})
+	if err != nil {
+		return ClientApiResponse{}, o.result(call, err, map[string]any{"description": string(resByte), "message": "Can't sent request", "error": err.Error()}), err
 	}
 
-	resByte, err := DoRequest(url, "GET", nil, appId)
+	err = o.unmarshal(resByte, &getObject)
 	if err != nil {
-		response.Data = map[string]any{"description": string(resByte), "message": "Can't sent request", "error": err.Error()}
-		response.Status = "error"
-		return ClientApiResponse{}, response, err
+		return ClientApiResponse{}, o.result(call, err, map[string]any{"description": string(resByte), "message": "Error while unmarshalling to object", "error": err.Error()}), err
 	}
 
-	err = json.Unmarshal(resByte, &getObject)
+	err = o.expand(call, arg, singleRows(getObject.Data.Data.Response))
 	if err != nil {
-		response.Data = map[string]any{"description": string(resByte), "message": "Error while unmarshalling to object", "error": err.Error()}
-		response.Status = "error"
-		return ClientApiResponse{}, response, err
+		return ClientApiResponse{}, o.result(call, err, map[string]any{"message": "Error while expanding relations", "error": err.Error()}), err
 	}
 
-	return getObject, response, nil
+	return getObject, o.result(call, nil, nil), nil
 }
-func (o *ObjectFunction) GetListAggregation(arg *Argument) (GetListAggregationClientApiResponse, Response, error) {
+func (o *ObjectFunction) GetListAggregation(arg *Argument, opts ...CallOption) (GetListAggregationClientApiResponse, Response, error) {
+	arg, call := o.prepare(arg, opts)
+
 	var (
-		response           Response
 		getListAggregation GetListAggregationClientApiResponse
 		url                = fmt.Sprintf("%s/v2/items/%s/aggregation", o.Cfg.BaseURL, arg.TableSlug)
 	)
 
-	var appId = o.Cfg.AppId
-	if arg.AppId != "" {
-		appId = arg.AppId
-	}
-
-	getListAggregationResponseInByte, err := DoRequest(url, "POST", arg.Request, appId)
+	getListAggregationResponseInByte, err := o.doRequest(call, apiRequest{method: "POST", url: url, body: arg.Request, kind: requestAggregate, tables: []string{arg.TableSlug}})
 	if err != nil {
-		response.Data = map[string]any{"description": string(getListAggregationResponseInByte), "message": "Can't sent request", "error": err.Error()}
-		response.Status = "error"
-		return GetListAggregationClientApiResponse{}, response, err
+		return GetListAggregationClientApiResponse{}, o.result(call, err, map[string]any{"description": string(getListAggregationResponseInByte), "message": "Can't sent request", "error": err.Error()}), err
 	}
 
-	err = json.Unmarshal(getListAggregationResponseInByte, &getListAggregation)
+	err = o.unmarshal(getListAggregationResponseInByte, &getListAggregation)
 	if err != nil {
-		response.Data = map[string]any{"description": string(getListAggregationResponseInByte), "message": "Error while unmarshalling get list object", "error": err.Error()}
-		response.Status = "error"
-		return GetListAggregationClientApiResponse{}, response, err
+		return GetListAggregationClientApiResponse{}, o.result(call, err, map[string]any{"description": string(getListAggregationResponseInByte), "message": "Error while unmarshalling get list object", "error": err.Error()}), err
 	}
 
-	return getListAggregation, response, nil
+	return getListAggregation, o.result(call, nil, nil), nil
 }
-func (o *ObjectFunction) AppendManyToMany(arg *Argument) (Response, error) {
-	var (
-		response Response
-		url      = fmt.Sprintf("%s/v2/items/many-to-many?from-ofs=%t", o.Cfg.BaseURL, arg.DisableFaas)
-	)
+func (o *ObjectFunction) AppendManyToMany(arg *Argument, opts ...CallOption) (Response, error) {
+	arg, call := o.prepare(arg, opts)
 
-	var appId = o.Cfg.AppId
-	if arg.AppId != "" {
-		appId = arg.AppId
-	}
+	var url = fmt.Sprintf("%s/v2/items/many-to-many?from-ofs=%t", o.Cfg.BaseURL, arg.DisableFaas)
 
-	_, err := DoRequest(url, "PUT", arg.Request.Data, appId)
+	_, err := o.doRequest(call, apiRequest{method: "PUT", url: url, body: arg.Request.Data, kind: requestWrite, tables: manyToManyTables(arg)})
 	if err != nil {
-		response.Data = map[string]any{"message": "Error while appending many-to-many object", "error": err.Error()}
-		response.Status = "error"
-		return response, err
+		return o.result(call, err, map[string]any{"message": "Error while appending many-to-many object", "error": err.Error()}), err
 	}
 
-	return response, nil
+	return o.result(call, nil, nil), nil
 }
-func (o *ObjectFunction) DeleteManyToMany(arg *Argument) (Response, error) {
-	var (
-		response Response
-		url      = fmt.Sprintf("%s/v2/items/many-to-many?from-ofs=%t", o.Cfg.BaseURL, arg.DisableFaas)
-	)
+func (o *ObjectFunction) DeleteManyToMany(arg *Argument, opts ...CallOption) (Response, error) {
+	arg, call := o.prepare(arg, opts)
 
-	var appId = o.Cfg.AppId
-	if arg.AppId != "" {
-		appId = arg.AppId
-	}
+	var url = fmt.Sprintf("%s/v2/items/many-to-many?from-ofs=%t", o.Cfg.BaseURL, arg.DisableFaas)
 
-	_, err := DoRequest(url, "DELETE", arg.Request.Data, appId)
+	_, err := o.doRequest(call, apiRequest{method: "DELETE", url: url, body: arg.Request.Data, kind: requestWrite, tables: manyToManyTables(arg)})
 	if err != nil {
-		response.Data = map[string]any{"message": "Error while deleting many-to-many object", "error": err.Error()}
-		response.Status = "error"
-		return response, err
+		return o.result(call, err, map[string]any{"message": "Error while deleting many-to-many object", "error": err.Error()}), err
 	}
 
-	return response, nil
+	return o.result(call, nil, nil), nil
 }
 
-func (o *ObjectFunction) Delete(arg *Argument) (Response, error) {
-	var (
-		response = Response{
-			Status: "done",
-		}
-		url = fmt.Sprintf("%s/v1/object/%s/%v?from-ofs=%t", o.Cfg.BaseURL, arg.TableSlug, arg.Request.Data["guid"], arg.DisableFaas)
-	)
+func (o *ObjectFunction) Delete(arg *Argument, opts ...CallOption) (Response, error) {
+	arg, call := o.prepare(arg, opts)
 
-	var appId = o.Cfg.AppId
-	if arg.AppId != "" {
-		appId = arg.AppId
-	}
+	var url = fmt.Sprintf("%s/v1/object/%s/%v?from-ofs=%t", o.Cfg.BaseURL, arg.TableSlug, arg.Request.Data["guid"], arg.DisableFaas)
 
-	_, err := DoRequest(url, "DELETE", Request{Data: map[string]any{}}, appId)
+	_, err := o.doRequest(call, apiRequest{method: "DELETE", url: url, body: Request{Data: map[string]any{}}, kind: requestWrite, tables: []string{arg.TableSlug}})
 	if err != nil {
-		response.Data = map[string]any{"message": "Error while deleting object", "error": err.Error()}
-		response.Status = "error"
-		return response, err
+		return o.result(call, err, map[string]any{"message": "Error while deleting object", "error": err.Error()}), err
 	}
 
-	return response, nil
+	return o.result(call, nil, nil), nil
 }
 
-func (o *ObjectFunction) MultipleDelete(arg *Argument) (Response, error) {
-	var (
-		response = Response{Status: "done"}
-		url      = fmt.Sprintf("%s/v1/object/%s/?from-ofs=%t", o.Cfg.BaseURL, arg.TableSlug, arg.DisableFaas)
-	)
+func (o *ObjectFunction) MultipleDelete(arg *Argument, opts ...CallOption) (Response, error) {
+	arg, call := o.prepare(arg, opts)
 
-	var appId = o.Cfg.AppId
-	if arg.AppId != "" {
-		appId = arg.AppId
-	}
+	var url = fmt.Sprintf("%s/v1/object/%s/?from-ofs=%t", o.Cfg.BaseURL, arg.TableSlug, arg.DisableFaas)
 
-	_, err := DoRequest(url, "DELETE", arg.Request.Data, appId)
+	_, err := o.doRequest(call, apiRequest{method: "DELETE", url: url, body: arg.Request.Data, kind: requestWrite, tables: []string{arg.TableSlug}})
 	if err != nil {
-		response.Data = map[string]any{"message": "Error while deleting objects", "error": err.Error()}
-		response.Status = "error"
-		return response, err
+		return o.result(call, err, map[string]any{"message": "Error while deleting objects", "error": err.Error()}), err
 	}
 
-	return response, nil
+	return o.result(call, nil, nil), nil
 }
-func (o *ObjectFunction) MultipleUpsert(arg *Argument) (ClientApiMultipleUpsertResponse, Response, error) {
+func (o *ObjectFunction) MultipleUpsert(arg *Argument, opts ...CallOption) (ClientApiMultipleUpsertResponse, Response, error) {
+	arg, call := o.prepare(arg, opts)
+
 	var (
-		response            = Response{Status: "done"}
 		multipleUpsertItems = ClientApiMultipleUpsertResponse{}
 		url                 = fmt.Sprintf("%s/v2/items/%s/upsert-many?from-ofs=%t", o.Cfg.BaseURL, arg.TableSlug, arg.DisableFaas)
 	)
 
-	var appId = o.Cfg.AppId
-	if arg.AppId != "" {
-		appId = arg.AppId
-	}
-
-	multipleUpsertItemsResponseInByte, err := DoRequest(url, "POST", arg.UpsertRequest, appId)
+	multipleUpsertItemsResponseInByte, err := o.doRequest(call, apiRequest{method: "POST", url: url, body: arg.UpsertRequest, kind: requestWrite, tables: []string{arg.TableSlug}})
 	if err != nil {
-		response.Data = map[string]any{"description": string(multipleUpsertItemsResponseInByte), "message": "Error while multiple upserting items", "error": err.Error()}
-		response.Status = "error"
-		return ClientApiMultipleUpsertResponse{}, response, err
+		return ClientApiMultipleUpsertResponse{}, o.result(call, err, map[string]any{"description": string(multipleUpsertItemsResponseInByte), "message": "Error while multiple upserting items", "error": err.Error()}), err
 	}
 
-	err = json.Unmarshal(multipleUpsertItemsResponseInByte, &multipleUpsertItems)
+	err = o.unmarshal(multipleUpsertItemsResponseInByte, &multipleUpsertItems)
 	if err != nil {
-		response.Data = map[string]any{"description": string(multipleUpsertItemsResponseInByte), "message": "Error while unmarshalling multiple upsert items", "error": err.Error()}
-		response.Status = "error"
-		return ClientApiMultipleUpsertResponse{}, response, err
+		return ClientApiMultipleUpsertResponse{}, o.result(call, err, map[string]any{"description": string(multipleUpsertItemsResponseInByte), "message": "Error while unmarshalling multiple upsert items", "error": err.Error()}), err
 	}
 
-	return multipleUpsertItems, response, nil
+	return multipleUpsertItems, o.result(call, nil, nil), nil
 }
 
 func (o *ObjectFunction) SendTelegram(text string) error {
 	client := &http.Client{}
 
+	botToken, err := o.botToken()
+	if err != nil {
+		return err
+	}
+
 	if ContainsLike(Mode, text) {
 		text = strings.Replace(text, "\n", "", -1)
 	} else {
@@ -464,15 +442,15 @@ func (o *ObjectFunction) SendTelegram(text string) error {
 	}
 
 	for _, e := range o.Cfg.AccountIds {
-		botUrl := fmt.Sprintf("https://api.telegram.org/bot"+o.Cfg.BotToken+"/sendMessage?chat_id="+e+"&text=%s", text)
+		botUrl := fmt.Sprintf("https://api.telegram.org/bot"+botToken+"/sendMessage?chat_id="+e+"&text=%s", text)
 		request, err := http.NewRequest("GET", botUrl, nil)
 		if err != nil {
-			return err
+			return o.redactError(err)
 		}
 
 		resp, err := client.Do(request)
 		if err != nil {
-			return err
+			return o.redactError(err)
 		}
 		resp.Body.Close()
 	}
@@ -480,14 +458,16 @@ func (o *ObjectFunction) SendTelegram(text string) error {
 	return nil
 }
 func (o *ObjectFunction) SendTelegramV2(text string) error {
+	text = o.alertText(text)
 
-	if !ContainsLike(Mode, text) {
-		text = fmt.Sprintf("%s >>> %s \n%s", o.Cfg.FunctionName, time.Now().Format(time.RFC3339), text)
+	botToken, err := o.botToken()
+	if err != nil {
+		return err
 	}
 
-	bot, err := tgbotapi.NewBotAPI(o.Cfg.BotToken)
+	bot, err := tgbotapi.NewBotAPI(botToken)
 	if err != nil {
-		return err
+		return o.redactError(err)
 	}
 
 	for _, e := range o.Cfg.AccountIds {
@@ -499,29 +479,42 @@ func (o *ObjectFunction) SendTelegramV2(text string) error {
 		msg := tgbotapi.NewMessage(chatID, text)
 		_, err = bot.Send(msg)
 		if err != nil {
-			return err
+			return o.redactError(err)
 		}
 	}
 	return nil
 }
 
+// alertText prefixes text with function name and time unless it is already a FaasLogger line
+func (o *ObjectFunction) alertText(text string) string {
+	if !ContainsLike(Mode, text) {
+		text = fmt.Sprintf("%s >>> %s \n%s", o.Cfg.FunctionName, time.Now().Format(time.RFC3339), text)
+	}
+	return text
+}
+
 func (o *ObjectFunction) SendTelegramFile(req []byte, filename string) error {
-	err := os.WriteFile(filename, req, 0644)
+	botToken, err := o.botToken()
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(filename, req, 0644)
 	if err != nil {
 		return err
 	}
 	defer os.Remove(filename)
 
 	for _, e := range o.Cfg.AccountIds {
-		bot, err := tgbotapiK.NewBotAPI(o.Cfg.BotToken)
+		bot, err := tgbotapiK.NewBotAPI(botToken)
 		if err != nil {
-			return err
+			return o.redactError(err)
 		}
 
 		message := tgbotapiK.NewDocumentUpload(cast.ToInt64(e), filename)
 		_, err = bot.Send(message)
 		if err != nil {
-			return err
+			return o.redactError(err)
 		}
 	}
 
@@ -530,66 +523,17 @@ func (o *ObjectFunction) SendTelegramFile(req []byte, filename string) error {
 
 /*
 SendNotification sends a notification to a specific FCM token using Firebase Cloud Messaging.
-It supports both Android and iOS platforms.
-Platform type should be 'android' or 'ios'
+It supports Android, iOS and Web platforms.
+Platform type should be 'android', 'ios', 'web' or 'auto'. 'auto' sends both notification
+and data blocks with config for every platform, useful when the token platform is unknown.
 */
 func (o *ObjectFunction) SendNotification(notification Notification) error {
-
-	opt := option.WithCredentialsJSON([]byte(o.Cfg.FirebaseConfig))
-
-	app, err := firebase.NewApp(context.Background(), nil, opt)
-	if err != nil {
-		return fmt.Errorf("error initializing app: %v", err)
-	}
-
-	client, err := app.Messaging(context.Background())
+	message, err := buildMessage(notification)
 	if err != nil {
-		return fmt.Errorf("error getting Messaging client: %v", err)
-	}
-
-	var message *messaging.Message
-
-	switch notification.PlatformType {
-	case "android":
-		message = &messaging.Message{
-			Token: notification.FcmToken,
-			Data: map[string]string{
-				"title": notification.Title,
-				"body":  notification.Body,
-			},
-			Android: &messaging.AndroidConfig{
-				Priority: "high",
-			},
-		}
-	case "ios":
-		message = &messaging.Message{
-			Token: notification.FcmToken,
-			Notification: &messaging.Notification{
-				Title: notification.Title,
-				Body:  notification.Body,
-			},
-			APNS: &messaging.APNSConfig{
-				Payload: &messaging.APNSPayload{
-					Aps: &messaging.Aps{
-						Sound:            "default",
-						ContentAvailable: true,
-					},
-				},
-				Headers: map[string]string{
-					"apns-priority": "10",
-				},
-			},
-		}
-	default:
-		return fmt.Errorf("unsupported platform type: %v", notification.PlatformType)
-	}
-
-	_, err = client.Send(context.Background(), message)
-	if err != nil {
-		return fmt.Errorf("error sending notification: %v", err)
+		return err
 	}
 
-	return nil
+	return o.sendMessage(message)
 }
 func (o *ObjectFunction) Config() *Config {
 	return o.Cfg
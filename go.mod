@@ -11,6 +11,7 @@ require (
 	github.com/spf13/cast v1.6.0
 	google.golang.org/api v0.247.0
 	gopkg.in/telegram-bot-api.v4 v4.6.4
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
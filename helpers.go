@@ -2,6 +2,7 @@ package ucodesdk
 
 import (
 	"bytes"
+	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
@@ -198,6 +199,18 @@ func VerifySHA256(data, hashedData string) bool {
 	return HashSHA256(data) == hashedData
 }
 
+// HmacSHA256 signs the input data with key using HMAC-SHA256 algorithm
+func HmacSHA256(data, key string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(data))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyHmacSHA256 verifies signature in constant time
+func VerifyHmacSHA256(data, key, signature string) bool {
+	return hmac.Equal([]byte(HmacSHA256(data, key)), []byte(signature))
+}
+
 // SpacefWithDigits formats a float64 into a human-readable string with specified decimal places.
 // Example: SpacefWithDigits(1234567.89, 1) returns "1 234 567.8"
 // Example: SpacefWithDigits(1234567.89, 4) returns "1 234 567.8900"
@@ -0,0 +1,82 @@
+package ucodesdk
+
+import (
+	"time"
+)
+
+const (
+	// IdempotencyKeyHeader carries key of logical write operation bound to the request, see WithCallIdempotencyKey
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	defaultIdempotencyWindow = 10 * time.Minute
+)
+
+/*
+WithIdempotency sets store keeping results of writes made with WithCallIdempotencyKey for window.
+Without it results are kept in memory of the client for 10 minutes.
+Shared store (e.g. Redis-like CacheBackend) makes keys work across function instances.
+*/
+func WithIdempotency(store CacheBackend, window time.Duration) Option {
+	return func(o *ObjectFunction) {
+		o.idempotencyStore = store
+		o.idempotencyWindow = window
+	}
+}
+
+/*
+WithCallIdempotencyKey marks write call as logical operation identified by key, e.g. id of the
+incoming event. The first successful result is returned for calls with the same key, method, url
+and body within the window instead of sending them again. Different requests made with the same key
+are sent separately: IdempotencyKeyHeader carries hash of the key bound to method, url and body,
+so the server tells them apart as well. Concurrent calls with the same key and request share one request.
+Writes with key are retried like reads.
+*/
+func WithCallIdempotencyKey(key string) CallOption {
+	return func(c *callOptions) {
+		c.idempotencyKey = key
+	}
+}
+
+// idempotentWrite returns stored result of the key or sends the write and stores its result
+func (o *ObjectFunction) idempotentWrite(call *callOptions, appId string, req apiRequest, data []byte) ([]byte, error) {
+	// key is bound to the request, so other writes of the same event are not answered with its result,
+	// the server receives the bound key too
+	call.idempotencyKey = HashSHA256(call.idempotencyKey + "\n" + req.method + "\n" + req.url + "\n" + HashSHA256(string(data)))
+
+	var (
+		store = o.idempotency()
+		key   = "ucode:idem:" + HashSHA256(appId+"\n"+call.idempotencyKey)
+	)
+
+	if respByte, ok := store.Get(call.ctx, key); ok {
+		return respByte, nil
+	}
+
+	return o.inflight.do(call.ctx, key, func() ([]byte, error) {
+		// the same key may have completed while waiting for the other call
+		if respByte, ok := store.Get(call.ctx, key); ok {
+			return respByte, nil
+		}
+
+		respByte, err := o.sendWithRetry(call, req, data)
+		if err == nil {
+			store.Set(call.ctx, key, respByte, o.idempotencyWindow)
+		}
+		return respByte, err
+	})
+}
+
+func (o *ObjectFunction) idempotency() CacheBackend {
+	o.idempotencyMu.Lock()
+	defer o.idempotencyMu.Unlock()
+
+	if o.idempotencyStore == nil {
+		o.idempotencyStore = NewMemoryCache(0)
+	}
+
+	if o.idempotencyWindow <= 0 {
+		o.idempotencyWindow = defaultIdempotencyWindow
+	}
+
+	return o.idempotencyStore
+}
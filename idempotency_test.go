@@ -0,0 +1,54 @@
+package ucodesdk
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestIdempotencyKeyBoundToRequest(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		keys []string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys = append(keys, r.Header.Get(IdempotencyKeyHeader))
+		mu.Unlock()
+		w.Write([]byte(`{"data": {"data": {"data": {"guid": "guid-1"}}}}`))
+	}))
+	defer server.Close()
+
+	var (
+		o       = New(&Config{BaseURL: server.URL, AppId: "app"})
+		order   = &Argument{TableSlug: "order", Request: Request{Data: map[string]interface{}{"name": "order"}}}
+		payment = &Argument{TableSlug: "payment", Request: Request{Data: map[string]interface{}{"amount": 10}}}
+	)
+
+	for _, arg := range []*Argument{order, payment, payment} {
+		if _, _, err := o.CreateObject(arg, WithCallIdempotencyKey("event-1")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// other instance without shared store sends the same key for the same request
+	other := New(&Config{BaseURL: server.URL, AppId: "app"})
+	if _, _, err := other.CreateObject(order, WithCallIdempotencyKey("event-1")); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(keys) != 3 {
+		t.Fatalf("%d requests sent, want repeated payment answered from store", len(keys))
+	}
+	if keys[0] == "" || keys[0] == "event-1" || keys[0] == keys[1] {
+		t.Errorf("different requests are sent with keys %q and %q", keys[0], keys[1])
+	}
+	if keys[2] != keys[0] {
+		t.Errorf("the same request is sent with keys %q and %q", keys[0], keys[2])
+	}
+}
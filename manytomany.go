@@ -0,0 +1,164 @@
+package ucodesdk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cast"
+)
+
+// Relation is many-to-many link between object IdFrom of TableFrom and objects IdTo of TableTo
+type Relation struct {
+	TableFrom string
+	IdFrom    string
+	TableTo   string
+	IdTo      []string
+
+	// Field is field of TableFrom objects holding linked ids, "<TableTo>_ids" by default
+	Field string
+}
+
+// RelationDiff lists ids linked and unlinked by ReplaceMany
+type RelationDiff struct {
+	Added   []string
+	Removed []string
+}
+
+func (r Relation) field() string {
+	if r.Field != "" {
+		return r.Field
+	}
+	return r.TableTo + "_ids"
+}
+
+func (r Relation) data(idTo []string) map[string]interface{} {
+	return map[string]interface{}{
+		"table_from": r.TableFrom,
+		"id_from":    r.IdFrom,
+		"table_to":   r.TableTo,
+		"id_to":      idTo,
+	}
+}
+
+func (r Relation) validate() error {
+	if r.TableFrom == "" || r.IdFrom == "" || r.TableTo == "" {
+		return fmt.Errorf("relation requires TableFrom, IdFrom and TableTo")
+	}
+	return nil
+}
+
+// LinkMany links relation.IdTo objects to relation.IdFrom with AppendManyToMany
+func (o *ObjectFunction) LinkMany(ctx context.Context, relation Relation, opts ...CallOption) (Response, error) {
+	return o.changeMany(ctx, relation, relation.IdTo, o.AppendManyToMany, opts)
+}
+
+// UnlinkMany removes links of relation.IdTo objects from relation.IdFrom with DeleteManyToMany
+func (o *ObjectFunction) UnlinkMany(ctx context.Context, relation Relation, opts ...CallOption) (Response, error) {
+	return o.changeMany(ctx, relation, relation.IdTo, o.DeleteManyToMany, opts)
+}
+
+/*
+ReplaceMany makes relation.IdTo the only objects linked to relation.IdFrom.
+Current links are read from TableFrom object bypassing the cache, only the difference is linked and unlinked.
+*/
+func (o *ObjectFunction) ReplaceMany(ctx context.Context, relation Relation, opts ...CallOption) (RelationDiff, Response, error) {
+	var diff RelationDiff
+
+	current, response, err := o.linkedIds(ctx, relation, append(opts[:len(opts):len(opts)], WithCallSkipCache()))
+	if err != nil {
+		return diff, response, err
+	}
+
+	diff.Added, diff.Removed = differenceStrings(relation.IdTo, current), differenceStrings(current, relation.IdTo)
+
+	if len(diff.Removed) > 0 {
+		if response, err = o.changeMany(ctx, relation, diff.Removed, o.DeleteManyToMany, opts); err != nil {
+			return RelationDiff{Removed: diff.Removed}, response, err
+		}
+	}
+
+	if len(diff.Added) > 0 {
+		if response, err = o.changeMany(ctx, relation, diff.Added, o.AppendManyToMany, opts); err != nil {
+			return diff, response, err
+		}
+	}
+
+	return diff, response, nil
+}
+
+// LinkedIds returns ids of TableTo objects linked to relation.IdFrom, relation.IdTo is ignored
+func (o *ObjectFunction) LinkedIds(ctx context.Context, relation Relation, opts ...CallOption) ([]string, error) {
+	ids, _, err := o.linkedIds(ctx, relation, opts)
+	return ids, err
+}
+
+// ListRelated returns TableTo objects linked to relation.IdFrom, relation.IdTo is ignored
+func (o *ObjectFunction) ListRelated(ctx context.Context, relation Relation, opts ...CallOption) (GetListClientApiResponse, Response, error) {
+	ids, response, err := o.linkedIds(ctx, relation, opts)
+	if err != nil || len(ids) == 0 {
+		return GetListClientApiResponse{}, response, err
+	}
+
+	return o.GetList(&Argument{
+		TableSlug: relation.TableTo,
+		Request:   Request{Data: map[string]interface{}{"guid": ids, "limit": len(ids)}},
+	}, append([]CallOption{WithCallContext(ctx)}, opts...)...)
+}
+
+func (o *ObjectFunction) linkedIds(ctx context.Context, relation Relation, opts []CallOption) ([]string, Response, error) {
+	if err := relation.validate(); err != nil {
+		return nil, o.failed(opts, err), err
+	}
+
+	object, response, err := o.GetSingle(&Argument{
+		TableSlug: relation.TableFrom,
+		Request:   Request{Data: map[string]interface{}{"guid": relation.IdFrom}},
+	}, append([]CallOption{WithCallContext(ctx)}, opts...)...)
+	if err != nil {
+		return nil, response, err
+	}
+
+	return cast.ToStringSlice(object.Data.Data.Response[relation.field()]), response, nil
+}
+
+func (o *ObjectFunction) changeMany(ctx context.Context, relation Relation, idTo []string, change func(arg *Argument, opts ...CallOption) (Response, error), opts []CallOption) (Response, error) {
+	if err := relation.validate(); err != nil {
+		return o.failed(opts, err), err
+	}
+
+	opts = append([]CallOption{WithCallContext(ctx)}, opts...)
+
+	if len(idTo) == 0 {
+		_, call := o.prepare(&Argument{}, opts)
+		return o.result(call, nil, nil), nil
+	}
+
+	return change(&Argument{Request: Request{Data: relation.data(idTo)}}, opts...)
+}
+
+// failed returns Response of the call that failed before sending request
+func (o *ObjectFunction) failed(opts []CallOption, err error) Response {
+	_, call := o.prepare(&Argument{}, opts)
+	return o.result(call, err, map[string]interface{}{"message": err.Error()})
+}
+
+// differenceStrings returns elements of a missing in b
+func differenceStrings(a, b []string) []string {
+	var (
+		exclude = make(map[string]bool, len(b))
+		result  []string
+	)
+
+	for _, value := range b {
+		exclude[value] = true
+	}
+
+	for _, value := range a {
+		if !exclude[value] {
+			result = append(result, value)
+			exclude[value] = true
+		}
+	}
+
+	return result
+}
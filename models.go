@@ -3,6 +3,7 @@ package ucodesdk
 import (
 	"net/http"
 	"net/url"
+	"time"
 )
 
 type (
@@ -83,6 +84,8 @@ type (
 		Data       map[string]interface{} `json:"data"`
 		Attributes map[string]interface{} `json:"attributes"`
 		Server     map[string]interface{} `json:"server"`
+
+		err error
 	}
 
 	// GetListClientApiResponse This is get list api response >>>>> GET_LIST, GET_LIST_SLIM
@@ -152,9 +155,81 @@ type (
 	}
 )
 
+// Notification platform types accepted by SendNotification
+const (
+	PlatformAndroid = "android"
+	PlatformIOS     = "ios"
+	PlatformWeb     = "web"
+	PlatformAuto    = "auto"
+)
+
 type Notification struct {
 	FcmToken     string
 	PlatformType string
 	Title        string
 	Body         string
+
+	// Data is delivered to the app as key/value payload alongside title and body
+	Data map[string]string
+	// ImageURL is shown in the notification on platforms that support images
+	ImageURL string
+	// ClickAction is the deep link (or Android activity) opened when the notification is tapped,
+	// it is sent in data as click_action. Web notifications open it only when it is https URL
+	ClickAction string
+	// WebLink is https URL opened by web notifications, ClickAction is used when it is empty
+	WebLink string
+	// TTL is how long FCM keeps the message if the device is offline, zero means FCM default
+	TTL time.Duration
+	// CollapseKey groups messages so only the latest one is delivered
+	CollapseKey string
+	// Badge is the app icon badge count, nil leaves the badge untouched
+	Badge *int
+	// ChannelID is the Android notification channel
+	ChannelID string
+	// Sound is the sound file to play, empty means "default"
+	Sound string
+}
+
+// NotificationBatchResult is the result of multicast sends and topic subscription calls.
+// InvalidTokens are tokens FCM reported as unregistered or malformed, they can be
+// removed from the tables they were read from.
+type NotificationBatchResult struct {
+	SuccessCount  int
+	FailureCount  int
+	InvalidTokens []string
+	// Errors maps every failed token to the reason returned by FCM
+	Errors map[string]string
+}
+
+type Email struct {
+	To      []string
+	Cc      []string
+	Bcc     []string
+	ReplyTo string
+	Subject string
+	// Text and HTML bodies, when both are set the message is sent as multipart/alternative
+	Text        string
+	HTML        string
+	Attachments []EmailAttachment
+	// TemplateData when not nil executes Subject and Text as text/template and HTML as html/template
+	TemplateData interface{}
+}
+
+type EmailAttachment struct {
+	Filename string
+	// ContentType is detected from Filename extension when empty
+	ContentType string
+	Data        []byte
+}
+
+// SlackMessage is Slack and Mattermost incoming webhook payload
+type SlackMessage struct {
+	Text      string `json:"text"`
+	Username  string `json:"username,omitempty"`
+	IconEmoji string `json:"icon_emoji,omitempty"`
+	Channel   string `json:"channel,omitempty"`
+}
+
+func (e *ResponseError) Error() string {
+	return e.ErrorMessage
 }
@@ -0,0 +1,472 @@
+package ucodesdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	firebase "firebase.google.com/go/v4"
+	"firebase.google.com/go/v4/messaging"
+	"google.golang.org/api/option"
+)
+
+const (
+	// FcmMulticastLimit is the maximum number of tokens FCM accepts in one multicast request
+	FcmMulticastLimit = 500
+	// FcmTopicLimit is the maximum number of tokens FCM accepts in one topic subscription request
+	FcmTopicLimit = 1000
+)
+
+/*
+SendMulticastNotification sends the same notification to many FCM tokens.
+Tokens are split into chunks of FcmMulticastLimit, so any number of tokens can be passed.
+Notification.FcmToken is ignored. Unregistered and invalid tokens are collected in
+NotificationBatchResult.InvalidTokens so the caller can prune them.
+*/
+func (o *ObjectFunction) SendMulticastNotification(notification Notification, tokens []string) (NotificationBatchResult, error) {
+	var result = NotificationBatchResult{Errors: map[string]string{}}
+
+	notification.FcmToken = ""
+	message, err := buildMessage(notification)
+	if err != nil {
+		return result, err
+	}
+
+	client, err := o.messagingClient(context.Background())
+	if err != nil {
+		return result, err
+	}
+
+	for _, chunk := range chunkStrings(tokens, FcmMulticastLimit) {
+		var (
+			response  *messaging.BatchResponse
+			multicast = &messaging.MulticastMessage{
+				Tokens:       chunk,
+				Data:         message.Data,
+				Notification: message.Notification,
+				Android:      message.Android,
+				Webpush:      message.Webpush,
+				APNS:         message.APNS,
+			}
+		)
+
+		if o.Cfg.FirebaseDryRun {
+			response, err = client.SendEachForMulticastDryRun(context.Background(), multicast)
+		} else {
+			response, err = client.SendEachForMulticast(context.Background(), multicast)
+		}
+		if err != nil {
+			return result, fmt.Errorf("error sending multicast notification: %v", err)
+		}
+
+		for i, res := range response.Responses {
+			if res.Success {
+				result.SuccessCount++
+				continue
+			}
+
+			result.FailureCount++
+			result.Errors[chunk[i]] = res.Error.Error()
+			if isInvalidToken(res.Error) {
+				result.InvalidTokens = append(result.InvalidTokens, chunk[i])
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// isInvalidToken tells errors of the token from other invalid argument errors, e.g. too big payload,
+// which would mark every token of the batch invalid
+func isInvalidToken(err error) bool {
+	if messaging.IsUnregistered(err) {
+		return true
+	}
+
+	if !messaging.IsInvalidArgument(err) {
+		return false
+	}
+
+	var message = strings.ToLower(err.Error())
+	return strings.Contains(message, "registration token") || strings.Contains(message, "invalidregistration")
+}
+
+// SendTopicNotification sends notification to every device subscribed to the topic.
+func (o *ObjectFunction) SendTopicNotification(notification Notification, topic string) error {
+	if topic == "" {
+		return errors.New("topic is required")
+	}
+
+	notification.FcmToken = ""
+	message, err := buildMessage(notification)
+	if err != nil {
+		return err
+	}
+	message.Topic = topic
+
+	return o.sendMessage(message)
+}
+
+/*
+SendConditionNotification sends notification to devices matching the topic condition.
+Example: "'stock' in topics && ('news' in topics || 'sale' in topics)"
+*/
+func (o *ObjectFunction) SendConditionNotification(notification Notification, condition string) error {
+	if condition == "" {
+		return errors.New("condition is required")
+	}
+
+	notification.FcmToken = ""
+	message, err := buildMessage(notification)
+	if err != nil {
+		return err
+	}
+	message.Condition = condition
+
+	return o.sendMessage(message)
+}
+
+// SubscribeToTopic subscribes tokens to the topic, chunked by FcmTopicLimit.
+func (o *ObjectFunction) SubscribeToTopic(tokens []string, topic string) (NotificationBatchResult, error) {
+	return o.manageTopic(tokens, topic, true)
+}
+
+// UnsubscribeFromTopic unsubscribes tokens from the topic, chunked by FcmTopicLimit.
+func (o *ObjectFunction) UnsubscribeFromTopic(tokens []string, topic string) (NotificationBatchResult, error) {
+	return o.manageTopic(tokens, topic, false)
+}
+
+func (o *ObjectFunction) manageTopic(tokens []string, topic string, subscribe bool) (NotificationBatchResult, error) {
+	var result = NotificationBatchResult{Errors: map[string]string{}}
+
+	client, err := o.messagingClient(context.Background())
+	if err != nil {
+		return result, err
+	}
+
+	for _, chunk := range chunkStrings(tokens, FcmTopicLimit) {
+		var response *messaging.TopicManagementResponse
+
+		if subscribe {
+			response, err = client.SubscribeToTopic(context.Background(), chunk, topic)
+		} else {
+			response, err = client.UnsubscribeFromTopic(context.Background(), chunk, topic)
+		}
+		if err != nil {
+			return result, fmt.Errorf("error managing topic subscription: %v", err)
+		}
+
+		result.SuccessCount += response.SuccessCount
+		result.FailureCount += response.FailureCount
+		for _, info := range response.Errors {
+			result.Errors[chunk[info.Index]] = info.Reason
+			if info.Reason == "NOT_FOUND" || info.Reason == "INVALID_ARGUMENT" {
+				result.InvalidTokens = append(result.InvalidTokens, chunk[info.Index])
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (o *ObjectFunction) sendMessage(message *messaging.Message) error {
+	client, err := o.messagingClient(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if o.Cfg.FirebaseDryRun {
+		_, err = client.SendDryRun(context.Background(), message)
+	} else {
+		_, err = client.Send(context.Background(), message)
+	}
+	if err != nil {
+		return fmt.Errorf("error sending notification: %v", err)
+	}
+
+	return nil
+}
+
+/*
+ValidateNotification checks notification on FCM without delivering it to the device.
+It returns the same errors SendNotification would return for an invalid token or payload.
+*/
+func (o *ObjectFunction) ValidateNotification(notification Notification) error {
+	message, err := buildMessage(notification)
+	if err != nil {
+		return err
+	}
+
+	client, err := o.messagingClient(context.Background())
+	if err != nil {
+		return err
+	}
+
+	_, err = client.SendDryRun(context.Background(), message)
+	if err != nil {
+		return fmt.Errorf("error validating notification: %v", err)
+	}
+
+	return nil
+}
+
+// messagingClient lazily initializes Firebase app and messaging client once per ObjectFunction.
+// Initialization is retried on the next call if it fails.
+func (o *ObjectFunction) messagingClient(ctx context.Context) (*messaging.Client, error) {
+	o.fcmMu.Lock()
+	defer o.fcmMu.Unlock()
+
+	if o.fcmClient != nil {
+		return o.fcmClient, nil
+	}
+
+	opts, err := o.firebaseOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	var conf *firebase.Config
+	if o.Cfg.FirebaseProjectId != "" {
+		conf = &firebase.Config{ProjectID: o.Cfg.FirebaseProjectId}
+	}
+
+	app, err := firebase.NewApp(ctx, conf, opts...)
+	if err != nil {
+		return nil, o.redactError(fmt.Errorf("error initializing app: %v", err))
+	}
+
+	client, err := app.Messaging(ctx)
+	if err != nil {
+		return nil, o.redactError(fmt.Errorf("error getting Messaging client: %v", err))
+	}
+
+	o.fcmClient = client
+	return client, nil
+}
+
+// firebaseOptions resolves credentials in order FirebaseConfig (or FirebaseConfigSource), FirebaseConfigEnv, FirebaseConfigPath.
+// Credentials are read once, rotated credentials need a new ObjectFunction.
+func (o *ObjectFunction) firebaseOptions() ([]option.ClientOption, error) {
+	var opts []option.ClientOption
+
+	firebaseConfig, err := o.firebaseConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case firebaseConfig != "":
+		opts = append(opts, option.WithCredentialsJSON([]byte(firebaseConfig)))
+	case o.Cfg.FirebaseConfigEnv != "":
+		credentials := os.Getenv(o.Cfg.FirebaseConfigEnv)
+		if credentials == "" {
+			return nil, fmt.Errorf("firebase credentials env %s is empty", o.Cfg.FirebaseConfigEnv)
+		}
+		o.rememberSecret(credentials)
+		opts = append(opts, option.WithCredentialsJSON([]byte(credentials)))
+	case o.Cfg.FirebaseConfigPath != "":
+		credentials, err := os.ReadFile(o.Cfg.FirebaseConfigPath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading firebase credentials: %v", err)
+		}
+		o.rememberSecret(string(credentials))
+		opts = append(opts, option.WithCredentialsJSON(credentials))
+	case o.Cfg.FirebaseEndpoint != "":
+		opts = append(opts, option.WithoutAuthentication())
+	}
+
+	if o.Cfg.FirebaseEndpoint != "" {
+		opts = append(opts, option.WithEndpoint(o.Cfg.FirebaseEndpoint))
+	}
+
+	return opts, nil
+}
+
+func chunkStrings(arr []string, size int) [][]string {
+	var chunks [][]string
+	for size < len(arr) {
+		chunks = append(chunks, arr[:size:size])
+		arr = arr[size:]
+	}
+	if len(arr) > 0 {
+		chunks = append(chunks, arr)
+	}
+	return chunks
+}
+
+// buildMessage converts Notification into FCM message for the requested platform type.
+//   - android: data-only message, the app renders title, body and extra keys itself
+//   - ios: APNS alert with sound, badge and mutable content for images, deep link in data
+//   - web: Webpush notification with https link
+//   - auto: notification and data blocks together with config for every platform
+func buildMessage(notification Notification) (*messaging.Message, error) {
+	var message = &messaging.Message{Token: notification.FcmToken}
+
+	switch notification.PlatformType {
+	case PlatformAndroid:
+		message.Data = notificationData(notification, true)
+		message.Android = androidConfig(notification, false)
+	case PlatformIOS:
+		message.Notification = fcmNotification(notification)
+		message.Data = notificationData(notification, false)
+		message.APNS = apnsConfig(notification)
+	case PlatformWeb:
+		message.Notification = fcmNotification(notification)
+		message.Data = notificationData(notification, false)
+		message.Webpush = webpushConfig(notification)
+	case PlatformAuto:
+		message.Notification = fcmNotification(notification)
+		message.Data = notificationData(notification, true)
+		message.Android = androidConfig(notification, true)
+		message.APNS = apnsConfig(notification)
+		message.Webpush = webpushConfig(notification)
+	default:
+		return nil, fmt.Errorf("unsupported platform type: %v", notification.PlatformType)
+	}
+
+	return message, nil
+}
+
+func fcmNotification(notification Notification) *messaging.Notification {
+	return &messaging.Notification{
+		Title:    notification.Title,
+		Body:     notification.Body,
+		ImageURL: notification.ImageURL,
+	}
+}
+
+// notificationData copies Notification.Data, with withContent title, body and
+// rich fields are added as well for apps that build the notification from data
+func notificationData(notification Notification, withContent bool) map[string]string {
+	var data = make(map[string]string, len(notification.Data)+6)
+	for key, value := range notification.Data {
+		data[key] = value
+	}
+
+	// deep link reaches the app through data, aps has no field for it
+	if _, ok := data["click_action"]; !ok && notification.ClickAction != "" {
+		data["click_action"] = notification.ClickAction
+	}
+
+	if !withContent {
+		if len(data) == 0 {
+			return nil
+		}
+		return data
+	}
+
+	data["title"] = notification.Title
+	data["body"] = notification.Body
+
+	var extra = map[string]string{
+		"image":      notification.ImageURL,
+		"channel_id": notification.ChannelID,
+		"sound":      notification.Sound,
+	}
+	for key, value := range extra {
+		if _, ok := data[key]; !ok && value != "" {
+			data[key] = value
+		}
+	}
+
+	return data
+}
+
+func androidConfig(notification Notification, withNotification bool) *messaging.AndroidConfig {
+	var config = &messaging.AndroidConfig{
+		Priority:    "high",
+		CollapseKey: notification.CollapseKey,
+	}
+
+	if notification.TTL > 0 {
+		var ttl = notification.TTL
+		config.TTL = &ttl
+	}
+
+	if withNotification {
+		config.Notification = &messaging.AndroidNotification{
+			ChannelID:         notification.ChannelID,
+			Sound:             notificationSound(notification),
+			ClickAction:       notification.ClickAction,
+			ImageURL:          notification.ImageURL,
+			NotificationCount: notification.Badge,
+		}
+	}
+
+	return config
+}
+
+func apnsConfig(notification Notification) *messaging.APNSConfig {
+	var headers = map[string]string{"apns-priority": "10"}
+
+	if notification.CollapseKey != "" {
+		headers["apns-collapse-id"] = notification.CollapseKey
+	}
+
+	if notification.TTL > 0 {
+		headers["apns-expiration"] = strconv.FormatInt(time.Now().Add(notification.TTL).Unix(), 10)
+	}
+
+	var config = &messaging.APNSConfig{
+		Headers: headers,
+		Payload: &messaging.APNSPayload{
+			Aps: &messaging.Aps{
+				Sound:            notificationSound(notification),
+				Badge:            notification.Badge,
+				ContentAvailable: true,
+				MutableContent:   notification.ImageURL != "",
+			},
+		},
+	}
+
+	if notification.ImageURL != "" {
+		config.FCMOptions = &messaging.APNSFCMOptions{ImageURL: notification.ImageURL}
+	}
+
+	return config
+}
+
+func webpushConfig(notification Notification) *messaging.WebpushConfig {
+	var headers = map[string]string{"Urgency": "high"}
+
+	if notification.TTL > 0 {
+		headers["TTL"] = strconv.FormatInt(int64(notification.TTL/time.Second), 10)
+	}
+
+	if notification.CollapseKey != "" {
+		headers["Topic"] = notification.CollapseKey
+	}
+
+	var config = &messaging.WebpushConfig{
+		Headers: headers,
+		Notification: &messaging.WebpushNotification{
+			Title: notification.Title,
+			Body:  notification.Body,
+			Image: notification.ImageURL,
+		},
+	}
+
+	// FCM rejects the whole message when web link is not https, app deep links are left to data
+	if link := firstNonEmpty(notification.WebLink, notification.ClickAction); isHttpsURL(link) {
+		config.FCMOptions = &messaging.WebpushFCMOptions{Link: link}
+	}
+
+	return config
+}
+
+func isHttpsURL(link string) bool {
+	parsed, err := url.Parse(link)
+	return err == nil && parsed.Scheme == "https" && parsed.Host != ""
+}
+
+func notificationSound(notification Notification) string {
+	if notification.Sound != "" {
+		return notification.Sound
+	}
+	return "default"
+}
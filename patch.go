@@ -0,0 +1,175 @@
+package ucodesdk
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// ErrPatchConflict is matched by errors.Is when stored object differs from the original given to Patch
+var ErrPatchConflict = errors.New("stored object was changed")
+
+// PatchConflictError is returned by Patch verifying stored object, see WithCallVerifyStored
+type PatchConflictError struct {
+	Field    string
+	Expected interface{}
+	Stored   interface{}
+}
+
+func (e *PatchConflictError) Error() string {
+	return fmt.Sprintf("stored object was changed: field %s is %v, expected %v", e.Field, e.Stored, e.Expected)
+}
+
+func (e *PatchConflictError) Is(target error) bool {
+	return target == ErrPatchConflict
+}
+
+// FieldChange is change of one field made by Patch
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// ChangeLog describes update made by Patch, it can be stored for auditing
+type ChangeLog struct {
+	TableSlug string        `json:"table_slug"`
+	Guid      string        `json:"guid"`
+	Changes   []FieldChange `json:"changes"`
+	RequestId string        `json:"request_id"`
+	At        time.Time     `json:"at"`
+}
+
+/*
+WithCallVerifyStored makes Patch read the stored object before writing and fail with
+*PatchConflictError when changed fields or given fields, e.g. "updated_at", differ from the original.
+*/
+func WithCallVerifyStored(fields ...string) CallOption {
+	return func(c *callOptions) {
+		c.verifyStored = true
+		c.verifyFields = append(c.verifyFields, fields...)
+	}
+}
+
+/*
+Patch updates object of arg.TableSlug sending only guid and fields of modified that differ from original.
+Objects are maps or structs with json tags of the table fields. Fields missing in modified are left as
+they are, set them to nil to clear. Nothing is sent when there is no change.
+*/
+func (o *ObjectFunction) Patch(arg *Argument, original, modified interface{}, opts ...CallOption) (ChangeLog, Response, error) {
+	_, call := o.prepare(arg, opts)
+
+	var changeLog = ChangeLog{TableSlug: arg.TableSlug, RequestId: call.requestId}
+
+	before, err := o.objectFields(original)
+	if err != nil {
+		return changeLog, o.result(call, err, map[string]any{"message": "Error while reading original object", "error": err.Error()}), err
+	}
+
+	after, err := o.objectFields(modified)
+	if err != nil {
+		return changeLog, o.result(call, err, map[string]any{"message": "Error while reading modified object", "error": err.Error()}), err
+	}
+
+	changeLog.Guid = firstNonEmpty(cast.ToString(after["guid"]), cast.ToString(before["guid"]))
+	if changeLog.Guid == "" {
+		err = fmt.Errorf("patched object has no guid")
+		return changeLog, o.result(call, err, map[string]any{"message": "Error while computing patch", "error": err.Error()}), err
+	}
+
+	changeLog.Changes = diffFields(before, after)
+	if len(changeLog.Changes) == 0 {
+		return changeLog, o.result(call, nil, nil), nil
+	}
+
+	// calls made by Patch share its request id
+	opts = append(opts[:len(opts):len(opts)], WithCallRequestId(call.requestId))
+
+	if call.verifyStored {
+		if response, err := o.verifyStored(call, arg, before, changeLog, opts); err != nil {
+			return changeLog, response, err
+		}
+	}
+
+	var data = map[string]interface{}{"guid": changeLog.Guid}
+	for _, change := range changeLog.Changes {
+		data[change.Field] = change.New
+	}
+
+	var update = *arg
+	update.Request.Data = data
+
+	_, response, err := o.UpdateObject(&update, opts...)
+	if err == nil {
+		changeLog.At = time.Now()
+	}
+
+	return changeLog, response, err
+}
+
+// verifyStored compares stored object with original bypassing the cache, fields missing in original are not compared
+func (o *ObjectFunction) verifyStored(call *callOptions, arg *Argument, before map[string]interface{}, changeLog ChangeLog, opts []CallOption) (Response, error) {
+	var single = *arg
+	single.Request.Data = map[string]interface{}{"guid": changeLog.Guid}
+
+	stored, response, err := o.GetSingle(&single, append(opts[:len(opts):len(opts)], WithCallSkipCache())...)
+	if err != nil {
+		return response, err
+	}
+
+	var fields = call.verifyFields
+	for _, change := range changeLog.Changes {
+		fields = append(fields[:len(fields):len(fields)], change.Field)
+	}
+
+	for _, field := range fields {
+		expected, ok := before[field]
+		if !ok {
+			continue
+		}
+
+		if value := stored.Data.Data.Response[field]; !reflect.DeepEqual(expected, value) {
+			err = &PatchConflictError{Field: field, Expected: expected, Stored: value}
+			return o.result(call, err, map[string]any{"message": "Error while verifying stored object", "error": err.Error()}), err
+		}
+	}
+
+	return response, nil
+}
+
+// objectFields returns fields of map or struct as they are sent to u-code API
+func (o *ObjectFunction) objectFields(object interface{}) (map[string]interface{}, error) {
+	body, err := o.marshal(object)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling object: %v", err)
+	}
+
+	var fields map[string]interface{}
+	if err = o.unmarshal(body, &fields); err != nil {
+		return nil, fmt.Errorf("error unmarshalling object: %v", err)
+	}
+
+	return fields, nil
+}
+
+// diffFields returns fields of after that differ from before, sorted by field name
+func diffFields(before, after map[string]interface{}) []FieldChange {
+	var changes []FieldChange
+
+	for field, value := range after {
+		if field == "guid" {
+			continue
+		}
+
+		if old, ok := before[field]; !ok || !reflect.DeepEqual(old, value) {
+			changes = append(changes, FieldChange{Field: field, Old: before[field], New: value})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
@@ -0,0 +1,214 @@
+package ucodesdk
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// EndpointClass groups u-code API endpoints for limits and stats
+type EndpointClass string
+
+const (
+	EndpointRead        EndpointClass = "read"
+	EndpointWrite       EndpointClass = "write"
+	EndpointAggregation EndpointClass = "aggregation"
+)
+
+/*
+Limit configures throttling of u-code API requests.
+Rate is requests per second refilled into a bucket of Burst tokens (Burst defaults to 1),
+MaxInFlight bounds concurrent requests. Zero values disable the corresponding limit.
+*/
+type Limit struct {
+	Rate        float64
+	Burst       int
+	MaxInFlight int
+}
+
+// ThrottleStats shows how requests of an endpoint class were held back by limits
+type ThrottleStats struct {
+	// Requests is number of requests that passed the limits
+	Requests int64
+	// Throttled is number of requests that had to wait
+	Throttled int64
+	// Wait is total time spent waiting
+	Wait time.Duration
+}
+
+// WithRateLimit limits every u-code API request, retries included
+func WithRateLimit(limit Limit) Option {
+	return func(o *ObjectFunction) {
+		o.limit = newLimiter(limit)
+	}
+}
+
+// WithEndpointLimit limits requests of class, it is applied together with WithRateLimit
+func WithEndpointLimit(class EndpointClass, limit Limit) Option {
+	return func(o *ObjectFunction) {
+		if o.classLimits == nil {
+			o.classLimits = map[EndpointClass]*limiter{}
+		}
+		o.classLimits[class] = newLimiter(limit)
+	}
+}
+
+// ThrottleStats returns throttling stats per endpoint class since the client was created
+func (o *ObjectFunction) ThrottleStats() map[EndpointClass]ThrottleStats {
+	o.throttleMu.Lock()
+	defer o.throttleMu.Unlock()
+
+	var stats = make(map[EndpointClass]ThrottleStats, len(o.throttleStats))
+	for class, value := range o.throttleStats {
+		stats[class] = value
+	}
+
+	return stats
+}
+
+// throttle waits for global and class limits, returned func releases in-flight slots
+func (o *ObjectFunction) throttle(ctx context.Context, class EndpointClass) (func(), error) {
+	// class limit is taken first, so saturated class does not hold global slots while waiting
+	var (
+		started  = time.Now()
+		limits   = []*limiter{o.classLimits[class], o.limit}
+		acquired []*limiter
+		waited   bool
+	)
+
+	var release = func() {
+		for _, l := range acquired {
+			l.release()
+		}
+	}
+
+	for _, l := range limits {
+		if l == nil {
+			continue
+		}
+
+		wait, err := l.acquire(ctx)
+		if err != nil {
+			release()
+			return nil, err
+		}
+
+		waited = waited || wait
+		acquired = append(acquired, l)
+	}
+
+	o.throttleMu.Lock()
+	defer o.throttleMu.Unlock()
+
+	if o.throttleStats == nil {
+		o.throttleStats = map[EndpointClass]ThrottleStats{}
+	}
+
+	stats := o.throttleStats[class]
+	stats.Requests++
+	if waited {
+		stats.Throttled++
+		stats.Wait += time.Since(started)
+	}
+	o.throttleStats[class] = stats
+
+	return release, nil
+}
+
+type limiter struct {
+	bucket *tokenBucket
+	slots  chan struct{}
+}
+
+func newLimiter(limit Limit) *limiter {
+	var l = &limiter{}
+
+	if limit.Rate > 0 {
+		burst := limit.Burst
+		if burst <= 0 {
+			burst = 1
+		}
+		l.bucket = &tokenBucket{rate: limit.Rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+	}
+
+	if limit.MaxInFlight > 0 {
+		l.slots = make(chan struct{}, limit.MaxInFlight)
+	}
+
+	return l
+}
+
+// acquire takes a token and an in-flight slot, reports whether it had to wait
+func (l *limiter) acquire(ctx context.Context) (bool, error) {
+	var waited bool
+
+	if l.bucket != nil {
+		wait, err := l.bucket.wait(ctx)
+		if err != nil {
+			return false, err
+		}
+		waited = wait
+	}
+
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		default:
+			waited = true
+			select {
+			case l.slots <- struct{}{}:
+			case <-ctx.Done():
+				return false, ctx.Err()
+			}
+		}
+	}
+
+	return waited, nil
+}
+
+func (l *limiter) release() {
+	if l.slots != nil {
+		<-l.slots
+	}
+}
+
+type tokenBucket struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// wait reserves a token and sleeps until it is available. Reservation is returned
+// to the bucket when ctx is done before that.
+func (b *tokenBucket) wait(ctx context.Context) (bool, error) {
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	b.tokens--
+	tokens := b.tokens
+	b.mu.Unlock()
+
+	if tokens >= 0 {
+		return false, nil
+	}
+
+	var timer = time.NewTimer(time.Duration(-tokens / b.rate * float64(time.Second)))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true, nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return false, ctx.Err()
+	}
+}
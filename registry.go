@@ -0,0 +1,203 @@
+package ucodesdk
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ClientRegistry holds ObjectFunction per tenant (project) built lazily from registered Config.
+// It is safe for concurrent use.
+type ClientRegistry struct {
+	opts []Option
+
+	mu      sync.RWMutex
+	tenants map[string]*tenant
+	factory func(name string) (*Config, error)
+}
+
+type tenant struct {
+	cfg    *Config
+	opts   []Option
+	client *ObjectFunction
+}
+
+// TenantResult is the result of operation run for one tenant by RunAll
+type TenantResult[T any] struct {
+	Tenant string
+	Value  T
+	Err    error
+}
+
+// NewClientRegistry creates registry, opts are applied to every tenant client before tenant options
+func NewClientRegistry(opts ...Option) *ClientRegistry {
+	return &ClientRegistry{
+		opts:    opts,
+		tenants: map[string]*tenant{},
+	}
+}
+
+// Register adds tenant config, client is built on first Get. Registering existing name replaces
+// its config, the previous client is closed.
+func (r *ClientRegistry) Register(name string, cfg *Config, opts ...Option) {
+	r.mu.Lock()
+	previous := r.tenants[name]
+	r.tenants[name] = &tenant{cfg: cfg, opts: opts}
+	r.mu.Unlock()
+
+	if previous != nil && previous.client != nil {
+		previous.client.Close()
+	}
+}
+
+// SetFactory sets function used by Get to load config of tenants that are not registered,
+// e.g. from a table of projects. Loaded tenants are registered.
+func (r *ClientRegistry) SetFactory(factory func(name string) (*Config, error)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.factory = factory
+}
+
+// Get returns client of tenant, building it on first use. Config is validated before building.
+func (r *ClientRegistry) Get(name string) (*ObjectFunction, error) {
+	r.mu.RLock()
+	t, ok := r.tenants[name]
+	if ok && t.client != nil {
+		r.mu.RUnlock()
+		return t.client, nil
+	}
+	factory := r.factory
+	r.mu.RUnlock()
+
+	if !ok {
+		if factory == nil {
+			return nil, fmt.Errorf("tenant %s is not registered", name)
+		}
+
+		cfg, err := factory(name)
+		if err != nil {
+			return nil, fmt.Errorf("error loading tenant %s: %v", name, err)
+		}
+		t = &tenant{cfg: cfg}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// other goroutine may have built, replaced or removed the tenant while the lock was released
+	current, registered := r.tenants[name]
+	switch {
+	case registered && current.client != nil:
+		return current.client, nil
+	case registered:
+		t = current
+	case ok:
+		return nil, fmt.Errorf("tenant %s is not registered", name)
+	}
+
+	if t.cfg == nil {
+		return nil, fmt.Errorf("tenant %s has no config", name)
+	}
+
+	if err := t.cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config of tenant %s: %v", name, err)
+	}
+
+	r.tenants[name] = t
+	t.client = New(t.cfg, append(append([]Option{}, r.opts...), t.opts...)...)
+	return t.client, nil
+}
+
+// Names returns sorted names of registered tenants
+func (r *ClientRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.tenants))
+	for name := range r.tenants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Remove deletes tenant and closes its client
+func (r *ClientRegistry) Remove(name string) {
+	r.mu.Lock()
+	t := r.tenants[name]
+	delete(r.tenants, name)
+	r.mu.Unlock()
+
+	if t != nil && t.client != nil {
+		t.client.Close()
+	}
+}
+
+// Close closes clients of every tenant, flushing their async queues
+func (r *ClientRegistry) Close() {
+	r.mu.RLock()
+	var clients []*ObjectFunction
+	for _, t := range r.tenants {
+		if t.client != nil {
+			clients = append(clients, t.client)
+		}
+	}
+	r.mu.RUnlock()
+
+	for _, client := range clients {
+		client.Close()
+	}
+}
+
+/*
+RunAll runs fn for every registered tenant with at most concurrency tenants at once
+(all at once when concurrency <= 0) and returns results in order of Names.
+Tenants whose client can't be built get the error in TenantResult.Err, fn is not called for them.
+Tenants not started before ctx is done get ctx.Err().
+*/
+func RunAll[T any](ctx context.Context, r *ClientRegistry, concurrency int, fn func(ctx context.Context, tenant string, o *ObjectFunction) (T, error)) []TenantResult[T] {
+	var (
+		names   = r.Names()
+		results = make([]TenantResult[T], len(names))
+		wg      sync.WaitGroup
+	)
+
+	if concurrency <= 0 || concurrency > len(names) {
+		concurrency = len(names)
+	}
+
+	var semaphore = make(chan struct{}, concurrency)
+
+	for i, name := range names {
+		results[i].Tenant = name
+
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(result *TenantResult[T]) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			client, err := r.Get(result.Tenant)
+			if err != nil {
+				result.Err = err
+				return
+			}
+
+			result.Value, result.Err = fn(ctx, result.Tenant, client)
+		}(&results[i])
+	}
+
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,43 @@
+package ucodesdk
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClientRegistryGet(t *testing.T) {
+	var registry = NewClientRegistry()
+	defer registry.Close()
+
+	registry.Register("shop", &Config{AppId: "shop", BaseURL: "https://api.example.com"})
+	registry.SetFactory(func(name string) (*Config, error) {
+		if name == "empty" {
+			return nil, nil
+		}
+		return &Config{AppId: name, BaseURL: "https://api.example.com"}, nil
+	})
+
+	client, err := registry.Get("shop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := registry.Get("shop"); again != client {
+		t.Error("client is built twice")
+	}
+
+	if _, err = registry.Get("empty"); err == nil || !strings.Contains(err.Error(), "no config") {
+		t.Errorf("error is %v, want missing config error", err)
+	}
+
+	if _, err = registry.Get("loaded"); err != nil {
+		t.Fatal(err)
+	}
+	if names := strings.Join(registry.Names(), ","); names != "loaded,shop" {
+		t.Errorf("names are %s, want tenant loaded by factory registered", names)
+	}
+
+	registry.Remove("shop")
+	if names := strings.Join(registry.Names(), ","); names != "loaded" {
+		t.Errorf("names are %s after Remove", names)
+	}
+}
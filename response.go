@@ -0,0 +1,84 @@
+package ucodesdk
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// RequestIdHeader carries id of the call, it is generated unless set with WithCallRequestId
+const RequestIdHeader = "X-Request-Id"
+
+const (
+	StatusDone  = "done"
+	StatusError = "error"
+)
+
+// WithCallRequestId sets request id of the call, e.g. id of the incoming platform request for tracing
+func WithCallRequestId(requestId string) CallOption {
+	return func(c *callOptions) {
+		c.requestId = requestId
+	}
+}
+
+/*
+Err returns error of failed call, nil when Status is not StatusError.
+Response returned by a method keeps the original error, so errors.Is and errors.As work.
+*/
+func (r Response) Err() error {
+	if r.Status != StatusError {
+		return nil
+	}
+
+	if r.err != nil {
+		return r.err
+	}
+
+	if r.Error != "" {
+		return errors.New(r.Error)
+	}
+
+	return errors.New("u-code request failed")
+}
+
+/*
+result builds Response of the call, it is the same for every method:
+Status is StatusDone or StatusError with Error message and data describing the failure,
+Server has request_id, status_code of the last attempt, attempts and duration_ms of the call.
+*/
+func (o *ObjectFunction) result(call *callOptions, err error, data map[string]interface{}) Response {
+	var response = Response{
+		Status: StatusDone,
+		Data:   data,
+		Server: map[string]interface{}{
+			"request_id":  call.requestId,
+			"status_code": call.statusCode,
+			"attempts":    call.attempts,
+			"duration_ms": time.Since(call.started).Milliseconds(),
+		},
+	}
+
+	if call.serverRequestId != "" && call.serverRequestId != call.requestId {
+		response.Server["server_request_id"] = call.serverRequestId
+	}
+
+	if err != nil {
+		response.Status, response.Error, response.err = StatusError, err.Error(), err
+
+		var responseErr *ResponseError
+		if errors.As(err, &responseErr) {
+			response.Server["status_code"] = responseErr.StatusCode
+		}
+	}
+
+	return response
+}
+
+func newRequestId() string {
+	var id = make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,295 @@
+package ucodesdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+// Secret holds sensitive value, it is redacted when printed or marshalled. Use Value to read it.
+type Secret string
+
+func (s Secret) Value() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return s.String()
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// SecretSource resolves secret value when it is needed
+type SecretSource interface {
+	Resolve(ctx context.Context) (Secret, error)
+}
+
+// EnvSecret reads secret from environment variable with the given name
+type EnvSecret string
+
+func (e EnvSecret) Resolve(ctx context.Context) (Secret, error) {
+	value, ok := os.LookupEnv(string(e))
+	if !ok || value == "" {
+		return "", fmt.Errorf("secret env %s is empty", string(e))
+	}
+	return Secret(value), nil
+}
+
+// FileSecret reads secret from file, trailing newline is trimmed
+type FileSecret string
+
+func (f FileSecret) Resolve(ctx context.Context) (Secret, error) {
+	data, err := os.ReadFile(string(f))
+	if err != nil {
+		return "", fmt.Errorf("error reading secret file: %v", err)
+	}
+	return Secret(strings.TrimRight(string(data), "\r\n")), nil
+}
+
+// DirSecret reads key from mounted secret directory, e.g. Kubernetes secret volume /var/secrets/bot_token
+func DirSecret(dir, key string) FileSecret {
+	return FileSecret(filepath.Join(dir, key))
+}
+
+// SecretFunc resolves secret with custom callback, e.g. from a vault client
+type SecretFunc func(ctx context.Context) (Secret, error)
+
+func (f SecretFunc) Resolve(ctx context.Context) (Secret, error) {
+	return f(ctx)
+}
+
+// SecretCache caches value of another source for ttl. After ttl the source is resolved again,
+// so rotated secrets are picked up without restarting the function.
+type SecretCache struct {
+	source SecretSource
+	ttl    time.Duration
+
+	mu      sync.Mutex
+	value   Secret
+	expires time.Time
+}
+
+// CachedSecret wraps source with cache, ttl <= 0 caches the value until Invalidate is called
+func CachedSecret(source SecretSource, ttl time.Duration) *SecretCache {
+	return &SecretCache{source: source, ttl: ttl}
+}
+
+func (c *SecretCache) Resolve(ctx context.Context) (Secret, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.value != "" && (c.ttl <= 0 || time.Now().Before(c.expires)) {
+		return c.value, nil
+	}
+
+	value, err := c.source.Resolve(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	c.value = value
+	c.expires = time.Now().Add(c.ttl)
+
+	return value, nil
+}
+
+// Invalidate drops cached value, e.g. after the API rejected it as expired
+func (c *SecretCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.value = ""
+}
+
+// String prints Config with secret fields redacted
+func (cfg Config) String() string {
+	type plain Config
+
+	var copied = plain(cfg)
+	for _, field := range []*string{&copied.AppId, &copied.BotToken, &copied.FirebaseConfig, &copied.SmtpPassword, &copied.WebhookSecret} {
+		if *field != "" {
+			*field = redacted
+		}
+	}
+
+	if len(copied.SlackWebhookURLs) > 0 {
+		copied.SlackWebhookURLs = []string{redacted}
+	}
+
+	// webhook headers usually carry Authorization tokens, names are kept
+	if len(copied.WebhookHeaders) > 0 {
+		copied.WebhookHeaders = make(map[string]string, len(cfg.WebhookHeaders))
+		for name := range cfg.WebhookHeaders {
+			copied.WebhookHeaders[name] = redacted
+		}
+	}
+
+	return fmt.Sprintf("%+v", copied)
+}
+
+// resolveSecret returns plain value when it is set, otherwise resolves source
+func (o *ObjectFunction) resolveSecret(ctx context.Context, plain string, source SecretSource) (string, error) {
+	if plain != "" || source == nil {
+		return plain, nil
+	}
+
+	value, err := source.Resolve(ctx)
+	if err != nil {
+		return "", o.redactError(err)
+	}
+
+	o.rememberSecret(value.Value())
+	return value.Value(), nil
+}
+
+func (o *ObjectFunction) appId(ctx context.Context, appId string) (string, error) {
+	return o.resolveSecret(ctx, appId, o.Cfg.AppIdSource)
+}
+
+func (o *ObjectFunction) botToken() (string, error) {
+	return o.resolveSecret(context.Background(), o.Cfg.BotToken, o.Cfg.BotTokenSource)
+}
+
+func (o *ObjectFunction) firebaseConfig() (string, error) {
+	return o.resolveSecret(context.Background(), o.Cfg.FirebaseConfig, o.Cfg.FirebaseConfigSource)
+}
+
+func (o *ObjectFunction) rememberSecret(values ...string) {
+	o.secretsMu.Lock()
+	defer o.secretsMu.Unlock()
+
+	if o.secrets == nil {
+		o.secrets = map[string]bool{}
+	}
+
+	for _, value := range values {
+		if len(value) >= 4 {
+			o.secrets[value] = true
+		}
+	}
+}
+
+// secretValues lists every secret known to ObjectFunction, longest first so that
+// secrets containing other secrets are replaced whole
+func (o *ObjectFunction) secretValues() []string {
+	var values = []string{o.Cfg.AppId, o.Cfg.BotToken, o.Cfg.FirebaseConfig, o.Cfg.SmtpPassword, o.Cfg.WebhookSecret}
+	values = append(values, o.Cfg.SlackWebhookURLs...)
+
+	// header value and its credentials without scheme, e.g. token of "Bearer <token>"
+	for _, value := range o.Cfg.WebhookHeaders {
+		values = append(values, value)
+		if _, credentials, ok := strings.Cut(value, " "); ok {
+			values = append(values, strings.TrimSpace(credentials))
+		}
+	}
+
+	if o.Cfg.FirebaseConfig != "" {
+		var credentials map[string]interface{}
+		if json.Unmarshal([]byte(o.Cfg.FirebaseConfig), &credentials) == nil {
+			for _, key := range []string{"private_key", "private_key_id"} {
+				if value, ok := credentials[key].(string); ok {
+					values = append(values, value)
+				}
+			}
+		}
+	}
+
+	o.secretsMu.Lock()
+	for value := range o.secrets {
+		values = append(values, value)
+	}
+	o.secretsMu.Unlock()
+
+	var result = values[:0]
+	for _, value := range values {
+		if len(value) >= 4 {
+			result = append(result, value)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return len(result[i]) > len(result[j]) })
+	return result
+}
+
+// redact replaces every known secret in text
+func (o *ObjectFunction) redact(text string) string {
+	for _, value := range o.secretValues() {
+		text = strings.ReplaceAll(text, value, redacted)
+	}
+	return text
+}
+
+/*
+redactError hides secrets in err and errors it wraps. *ResponseError and *url.Error, e.g. of a request
+to bot token URL, are replaced with redacted copies, other errors holding secrets with wrappers having
+their message redacted. errors.Is and errors.As work through the wrappers but never reach an error holding a secret.
+*/
+func (o *ObjectFunction) redactError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var message = o.redact(err.Error())
+	if message == err.Error() {
+		return err
+	}
+
+	switch err := err.(type) {
+	case *ResponseError:
+		copied := *err
+		copied.ErrorMessage = o.redact(copied.ErrorMessage)
+		return &copied
+	case *url.Error:
+		copied := *err
+		copied.URL, copied.Err = o.redact(copied.URL), o.redactError(copied.Err)
+		return &copied
+	}
+
+	var wrapped []error
+	switch err := err.(type) {
+	case interface{ Unwrap() error }:
+		wrapped = []error{err.Unwrap()}
+	case interface{ Unwrap() []error }:
+		wrapped = err.Unwrap()
+	}
+
+	var redactedErr = &redactedError{message: message}
+	for _, cause := range wrapped {
+		if cause != nil {
+			redactedErr.wrapped = append(redactedErr.wrapped, o.redactError(cause))
+		}
+	}
+
+	return redactedErr
+}
+
+type redactedError struct {
+	message string
+	wrapped []error
+}
+
+func (e *redactedError) Error() string {
+	return e.message
+}
+
+func (e *redactedError) Unwrap() []error {
+	return e.wrapped
+}
@@ -0,0 +1,62 @@
+package ucodesdk
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestConfigStringRedactsSecrets(t *testing.T) {
+	var cfg = Config{
+		AppId:          "app-secret",
+		BotToken:       "bot-secret",
+		SmtpPassword:   "smtp-secret",
+		WebhookHeaders: map[string]string{"Authorization": "Bearer header-secret"},
+	}
+
+	var printed = cfg.String()
+	for _, secret := range []string{"app-secret", "bot-secret", "smtp-secret", "header-secret"} {
+		if strings.Contains(printed, secret) {
+			t.Errorf("%s is printed: %s", secret, printed)
+		}
+	}
+
+	if !strings.Contains(printed, "Authorization") {
+		t.Errorf("header name is not printed: %s", printed)
+	}
+}
+
+func TestRedactError(t *testing.T) {
+	var o = New(&Config{
+		BotToken:       "123456:bot-token",
+		WebhookHeaders: map[string]string{"Authorization": "Bearer header-token"},
+	})
+
+	var (
+		requestErr = &url.Error{Op: "Get", URL: "https://api.telegram.org/bot123456:bot-token/sendMessage", Err: io.ErrUnexpectedEOF}
+		err        = o.redactError(fmt.Errorf("error sending alert with header-token: %w", requestErr))
+	)
+
+	if strings.Contains(err.Error(), "bot-token") || strings.Contains(err.Error(), "header-token") {
+		t.Errorf("message is not redacted: %v", err)
+	}
+
+	var unwrapped *url.Error
+	if !errors.As(err, &unwrapped) {
+		t.Fatal("*url.Error is not found")
+	}
+	if strings.Contains(unwrapped.URL, "bot-token") {
+		t.Errorf("unwrapped url is not redacted: %s", unwrapped.URL)
+	}
+
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Error("wrapped sentinel is not found")
+	}
+
+	if plain := errors.New("no secrets"); o.redactError(plain) != plain {
+		t.Error("error without secrets is replaced")
+	}
+}
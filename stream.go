@@ -0,0 +1,216 @@
+package ucodesdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+/*
+GetListStream works like GetList but decodes rows of the response one by one and passes them to fn
+without keeping the whole body in memory. Returning error from fn stops the stream with that error.
+Count of the response is returned when the API sent it.
+Streamed calls bypass client cache and coalescing, they are retried only before the first row.
+*/
+func (o *ObjectFunction) GetListStream(arg *Argument, fn func(row map[string]interface{}) error, opts ...CallOption) (int, error) {
+	arg, call := o.prepare(arg, opts)
+	return o.doStream(call, o.getListRequest(arg), fn)
+}
+
+// GetListSlimStream works like GetListSlim, see GetListStream
+func (o *ObjectFunction) GetListSlimStream(arg *Argument, fn func(row map[string]interface{}) error, opts ...CallOption) (int, error) {
+	arg, call := o.prepare(arg, opts)
+
+	req, err := o.getListSlimRequest(arg)
+	if err != nil {
+		return 0, fmt.Errorf("error marshalling get list slim request: %v", err)
+	}
+
+	return o.doStream(call, req, fn)
+}
+
+// RowStream delivers rows of streamed list through channel, see StreamList
+type RowStream struct {
+	rows  chan map[string]interface{}
+	done  chan struct{}
+	count int
+	err   error
+}
+
+// Rows is closed when the stream is finished
+func (s *RowStream) Rows() <-chan map[string]interface{} {
+	return s.rows
+}
+
+// Wait blocks until the stream is finished and returns count of the response and stream error
+func (s *RowStream) Wait() (int, error) {
+	<-s.done
+	return s.count, s.err
+}
+
+/*
+StreamList streams rows of GetList through channel. Rows have to be read until the channel is closed
+or the stream has to be cancelled with context passed in WithCallContext.
+*/
+func (o *ObjectFunction) StreamList(arg *Argument, opts ...CallOption) *RowStream {
+	arg, call := o.prepare(arg, opts)
+
+	var (
+		req    = o.getListRequest(arg)
+		stream = &RowStream{rows: make(chan map[string]interface{}), done: make(chan struct{})}
+	)
+
+	go func() {
+		defer close(stream.done)
+		defer close(stream.rows)
+
+		stream.count, stream.err = o.doStream(call, req, func(row map[string]interface{}) error {
+			select {
+			case stream.rows <- row:
+				return nil
+			case <-call.ctx.Done():
+				return call.ctx.Err()
+			}
+		})
+	}()
+
+	return stream
+}
+
+func (o *ObjectFunction) doStream(call *callOptions, req apiRequest, fn func(row map[string]interface{}) error) (int, error) {
+	appId, err := o.appId(call.ctx, call.appId)
+	if err != nil {
+		return 0, err
+	}
+	call.appId = appId
+	o.rememberSecret(appId)
+
+	data, err := o.requestData(req)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		count     int
+		streamErr error
+	)
+
+	// errors after the response started are not retried, rows may have been delivered already
+	err = o.withRetry(call, req, data, func(body io.Reader, size int64) error {
+		count, streamErr = decodeList(body, fn)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return count, o.redactError(streamErr)
+}
+
+// decodeList reads {"data": {"data": {"count": n, "response": [...]}}} passing every row to fn
+func decodeList(body io.Reader, fn func(row map[string]interface{}) error) (int, error) {
+	var (
+		decoder     = json.NewDecoder(body)
+		count       int
+		callbackErr error
+	)
+
+	err := walkObject(decoder, func(key string) error {
+		if key != "data" {
+			return skipValue(decoder)
+		}
+
+		return walkObject(decoder, func(key string) error {
+			if key != "data" {
+				return skipValue(decoder)
+			}
+
+			return walkObject(decoder, func(key string) error {
+				switch key {
+				case "count":
+					return decoder.Decode(&count)
+				case "response":
+					return decodeRows(decoder, func(row map[string]interface{}) error {
+						callbackErr = fn(row)
+						return callbackErr
+					})
+				default:
+					return skipValue(decoder)
+				}
+			})
+		})
+	})
+	if callbackErr != nil {
+		return count, callbackErr
+	}
+
+	if err != nil {
+		return count, fmt.Errorf("error decoding list stream: %w", err)
+	}
+
+	return count, nil
+}
+
+// walkObject calls field for every key of the next object, field has to consume the value. null is skipped.
+func walkObject(decoder *json.Decoder, field func(key string) error) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	if token == nil {
+		return nil
+	}
+
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("expected object, got %v", token)
+	}
+
+	for decoder.More() {
+		token, err = decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		if err = field(token.(string)); err != nil {
+			return err
+		}
+	}
+
+	_, err = decoder.Token()
+	return err
+}
+
+func decodeRows(decoder *json.Decoder, fn func(row map[string]interface{}) error) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	if token == nil {
+		return nil
+	}
+
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("expected array of rows, got %v", token)
+	}
+
+	for decoder.More() {
+		var row map[string]interface{}
+		if err = decoder.Decode(&row); err != nil {
+			return err
+		}
+
+		if err = fn(row); err != nil {
+			return err
+		}
+	}
+
+	_, err = decoder.Token()
+	return err
+}
+
+func skipValue(decoder *json.Decoder) error {
+	var value json.RawMessage
+	return decoder.Decode(&value)
+}
@@ -0,0 +1,271 @@
+package ucodesdk
+
+import (
+	"bytes"
+	"fmt"
+	htmlTemplate "html/template"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/spf13/cast"
+)
+
+const (
+	LocaleUz = "uz"
+	LocaleRu = "ru"
+	LocaleEn = "en"
+)
+
+// MessageTemplate is source of one localized message. Title and Body are text/template,
+// HTML is html/template. Besides builtins templates can use:
+//   - spacef: {{spacef .Amount}} -> "1 234 567.89"
+//   - spacefd: {{spacefd .Amount 2}} -> "1 234 567.89"
+//   - plural: {{plural .Count "товар" "товара" "товаров"}} forms chosen by template locale
+//
+// Numbers given to them may be of any int or float type or numeric strings.
+type MessageTemplate struct {
+	Title string
+	Body  string
+	HTML  string
+}
+
+// RenderedMessage is the result of TemplateRegistry.Render ready for any notification channel
+type RenderedMessage struct {
+	Locale string
+	Title  string
+	Body   string
+	HTML   string
+}
+
+type parsedTemplate struct {
+	title *template.Template
+	body  *template.Template
+	html  *htmlTemplate.Template
+}
+
+// TemplateRegistry holds message templates keyed by name and locale.
+// It is safe for concurrent use, templates are parsed once on Add.
+type TemplateRegistry struct {
+	DefaultLocale string
+
+	mu        sync.RWMutex
+	templates map[string]map[string]*parsedTemplate
+	fallbacks map[string][]string
+}
+
+func NewTemplateRegistry(defaultLocale string) *TemplateRegistry {
+	return &TemplateRegistry{
+		DefaultLocale: defaultLocale,
+		templates:     map[string]map[string]*parsedTemplate{},
+		fallbacks:     map[string][]string{},
+	}
+}
+
+// SetFallback sets locales tried in order when template is missing for locale.
+// Example: SetFallback("uz", "ru", "en")
+func (r *TemplateRegistry) SetFallback(locale string, chain ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.fallbacks[locale] = chain
+}
+
+// Add parses and registers template under name and locale, replacing existing one.
+func (r *TemplateRegistry) Add(name, locale string, source MessageTemplate) error {
+	var (
+		parsed = &parsedTemplate{}
+		funcs  = templateFuncs(locale)
+		err    error
+	)
+
+	if source.Title != "" {
+		if parsed.title, err = template.New(name).Funcs(funcs).Parse(source.Title); err != nil {
+			return fmt.Errorf("error parsing %s/%s title template: %v", name, locale, err)
+		}
+	}
+
+	if source.Body != "" {
+		if parsed.body, err = template.New(name).Funcs(funcs).Parse(source.Body); err != nil {
+			return fmt.Errorf("error parsing %s/%s body template: %v", name, locale, err)
+		}
+	}
+
+	if source.HTML != "" {
+		if parsed.html, err = htmlTemplate.New(name).Funcs(htmlTemplate.FuncMap(funcs)).Parse(source.HTML); err != nil {
+			return fmt.Errorf("error parsing %s/%s html template: %v", name, locale, err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.templates[name] == nil {
+		r.templates[name] = map[string]*parsedTemplate{}
+	}
+	r.templates[name][locale] = parsed
+
+	return nil
+}
+
+/*
+Render executes template name for locale with data.
+Locales are tried in order: locale, its fallback chain, base language ("uz-Cyrl" -> "uz"), DefaultLocale.
+*/
+func (r *TemplateRegistry) Render(name, locale string, data interface{}) (RenderedMessage, error) {
+	r.mu.RLock()
+	var (
+		parsed   *parsedTemplate
+		resolved string
+	)
+	for _, candidate := range r.localeChain(locale) {
+		if parsed = r.templates[name][candidate]; parsed != nil {
+			resolved = candidate
+			break
+		}
+	}
+	r.mu.RUnlock()
+
+	if parsed == nil {
+		return RenderedMessage{}, fmt.Errorf("template %s not found for locale %s", name, locale)
+	}
+
+	var (
+		message = RenderedMessage{Locale: resolved}
+		buf     bytes.Buffer
+	)
+
+	for _, item := range []struct {
+		tmpl   *template.Template
+		target *string
+	}{{parsed.title, &message.Title}, {parsed.body, &message.Body}} {
+		if item.tmpl == nil {
+			continue
+		}
+
+		buf.Reset()
+		if err := item.tmpl.Execute(&buf, data); err != nil {
+			return RenderedMessage{}, fmt.Errorf("error executing %s/%s template: %v", name, resolved, err)
+		}
+		*item.target = buf.String()
+	}
+
+	if parsed.html != nil {
+		buf.Reset()
+		if err := parsed.html.Execute(&buf, data); err != nil {
+			return RenderedMessage{}, fmt.Errorf("error executing %s/%s html template: %v", name, resolved, err)
+		}
+		message.HTML = buf.String()
+	}
+
+	return message, nil
+}
+
+func (r *TemplateRegistry) localeChain(locale string) []string {
+	var chain = []string{locale}
+	chain = append(chain, r.fallbacks[locale]...)
+
+	if i := strings.IndexAny(locale, "-_"); i > 0 {
+		chain = append(chain, locale[:i])
+		chain = append(chain, r.fallbacks[locale[:i]]...)
+	}
+
+	return append(chain, r.DefaultLocale)
+}
+
+// Notification fills Title and Body of notification with rendered message
+func (m RenderedMessage) Notification(notification Notification) Notification {
+	notification.Title = m.Title
+	notification.Body = m.Body
+	return notification
+}
+
+// Email fills Subject, Text and HTML of email with rendered message
+func (m RenderedMessage) Email(email Email) Email {
+	email.Subject = m.Title
+	email.Text = m.Body
+	email.HTML = m.HTML
+	return email
+}
+
+// Text joins title and body for text channels like Telegram, Slack and webhooks
+func (m RenderedMessage) Text() string {
+	if m.Title == "" {
+		return m.Body
+	}
+	if m.Body == "" {
+		return m.Title
+	}
+	return m.Title + "\n" + m.Body
+}
+
+// templateFuncs converts template arguments with cast, text/template passes numbers of data as they are
+func templateFuncs(locale string) template.FuncMap {
+	return template.FuncMap{
+		"spacef": func(v interface{}) (string, error) {
+			f, err := cast.ToFloat64E(v)
+			if err != nil {
+				return "", fmt.Errorf("spacef: %v", err)
+			}
+			return Spacef(f), nil
+		},
+		"spacefd": func(v, decimals interface{}) (string, error) {
+			f, err := cast.ToFloat64E(v)
+			if err != nil {
+				return "", fmt.Errorf("spacefd: %v", err)
+			}
+			d, err := cast.ToIntE(decimals)
+			if err != nil {
+				return "", fmt.Errorf("spacefd: %v", err)
+			}
+			return SpacefWithDigits(f, d), nil
+		},
+		"plural": func(v interface{}, forms ...string) (string, error) {
+			n, err := cast.ToIntE(v)
+			if err != nil {
+				return "", fmt.Errorf("plural: %v", err)
+			}
+			return Plural(locale, n, forms...), nil
+		},
+	}
+}
+
+/*
+Plural picks word form for n by locale rules.
+  - ru: forms "one", "few", "many" - 1 товар, 2 товара, 5 товаров
+  - en, uz and others: forms "one", "other" - 1 item, 2 items
+
+Missing forms fall back to the last given one.
+*/
+func Plural(locale string, n int, forms ...string) string {
+	if len(forms) == 0 {
+		return ""
+	}
+
+	if n < 0 {
+		n = -n
+	}
+
+	var index int
+	switch strings.ToLower(locale[:min(2, len(locale))]) {
+	case LocaleRu:
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			index = 0
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			index = 1
+		default:
+			index = 2
+		}
+	default:
+		if n != 1 {
+			index = 1
+		}
+	}
+
+	if index >= len(forms) {
+		index = len(forms) - 1
+	}
+
+	return forms[index]
+}
@@ -0,0 +1,64 @@
+package ucodesdk
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTemplateRegistryRenderIntegers(t *testing.T) {
+	var registry = NewTemplateRegistry(LocaleEn)
+
+	var sources = map[string]MessageTemplate{
+		LocaleRu: {Title: "Заказ {{.Id}}", Body: `{{.Count}} {{plural .Count "товар" "товара" "товаров"}} на {{spacef .Amount}} сум`},
+		LocaleUz: {Title: "Buyurtma {{.Id}}", Body: `{{.Count}} {{plural .Count "mahsulot"}} {{spacefd .Amount 2}} so'm`},
+		LocaleEn: {Title: "Order {{.Id}}", Body: `{{.Count}} {{plural .Count "item" "items"}} for {{spacefd .Amount 2}}`, HTML: "<b>{{spacef .Amount}}</b>"},
+	}
+	for locale, source := range sources {
+		if err := registry.Add("receipt", locale, source); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var data = map[string]interface{}{"Id": 42, "Count": int64(3), "Amount": 1234567}
+
+	var tests = []struct {
+		locale string
+		title  string
+		body   string
+	}{
+		{LocaleRu, "Заказ 42", "3 товара на 1 234 567 сум"},
+		{LocaleUz, "Buyurtma 42", "3 mahsulot 1 234 567 so'm"},
+		{LocaleEn, "Order 42", "3 items for 1 234 567"},
+	}
+
+	for _, test := range tests {
+		message, err := registry.Render("receipt", test.locale, data)
+		if err != nil {
+			t.Fatalf("%s: %v", test.locale, err)
+		}
+
+		if message.Title != test.title || message.Body != test.body {
+			t.Errorf("%s: rendered %q / %q, want %q / %q", test.locale, message.Title, message.Body, test.title, test.body)
+		}
+	}
+
+	message, err := registry.Render("receipt", LocaleEn, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.HTML != "<b>1 234 567</b>" {
+		t.Errorf("html is %q", message.HTML)
+	}
+}
+
+func TestTemplateRegistryRenderConversionError(t *testing.T) {
+	var registry = NewTemplateRegistry(LocaleEn)
+	if err := registry.Add("receipt", LocaleEn, MessageTemplate{Body: "{{spacef .Amount}}"}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := registry.Render("receipt", LocaleEn, map[string]interface{}{"Amount": "a lot"})
+	if err == nil || !strings.Contains(err.Error(), "spacef") {
+		t.Errorf("error is %v, want spacef conversion error", err)
+	}
+}
@@ -0,0 +1,163 @@
+package ucodesdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// WebhookSignatureHeader carries hex HMAC-SHA256 of "<timestamp>.<body>" signed with Config.WebhookSecret
+	WebhookSignatureHeader = "X-Ucode-Signature"
+	// WebhookTimestampHeader carries unix timestamp used in the signature, receivers should reject old ones
+	WebhookTimestampHeader = "X-Ucode-Timestamp"
+
+	defaultWebhookTimeout = 10 * time.Second
+)
+
+/*
+SendWebhook posts payload as JSON to every Config.WebhookURLs.
+When Config.WebhookSecret is set the request is signed, see WebhookSignatureHeader.
+Failed deliveries (network errors, 429 and 5xx) are retried Config.WebhookRetries times
+with exponential backoff. Every url is tried, errors are joined.
+*/
+func (o *ObjectFunction) SendWebhook(payload interface{}) error {
+	return o.SendWebhookContext(context.Background(), payload)
+}
+
+// SendWebhookContext works like SendWebhook, requests and waits between retries are cancelled with ctx
+func (o *ObjectFunction) SendWebhookContext(ctx context.Context, payload interface{}) error {
+	if len(o.Cfg.WebhookURLs) == 0 {
+		return errors.New("webhook urls are not configured")
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error marshalling webhook payload: %v", err)
+	}
+
+	var errs []error
+	for _, url := range o.Cfg.WebhookURLs {
+		if err = o.postWebhook(ctx, url, body, true); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return o.redactError(errors.Join(errs...))
+}
+
+// slackEscaper escapes control characters of Slack text, so text can not add mentions or links
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+/*
+SendSlack mirrors alert text to Slack or Mattermost incoming webhooks from Config.SlackWebhookURLs.
+Text is formatted the same way as in SendTelegramV2 and escaped, so it is shown as is.
+*/
+func (o *ObjectFunction) SendSlack(text string) error {
+	return o.SendSlackContext(context.Background(), text)
+}
+
+// SendSlackContext works like SendSlack, requests and waits between retries are cancelled with ctx
+func (o *ObjectFunction) SendSlackContext(ctx context.Context, text string) error {
+	return o.SendSlackMessageContext(ctx, SlackMessage{Text: slackEscaper.Replace(o.alertText(text)), Username: o.Cfg.FunctionName})
+}
+
+// SendSlackMessage posts message as is to every Config.SlackWebhookURLs, Text may use Slack markup.
+func (o *ObjectFunction) SendSlackMessage(message SlackMessage) error {
+	return o.SendSlackMessageContext(context.Background(), message)
+}
+
+// SendSlackMessageContext works like SendSlackMessage, requests and waits between retries are cancelled with ctx
+func (o *ObjectFunction) SendSlackMessageContext(ctx context.Context, message SlackMessage) error {
+	if len(o.Cfg.SlackWebhookURLs) == 0 {
+		return errors.New("slack webhook urls are not configured")
+	}
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("error marshalling slack message: %v", err)
+	}
+
+	var errs []error
+	for _, url := range o.Cfg.SlackWebhookURLs {
+		if err = o.postWebhook(ctx, url, body, false); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return o.redactError(errors.Join(errs...))
+}
+
+func (o *ObjectFunction) postWebhook(ctx context.Context, url string, body []byte, sign bool) error {
+	var (
+		client  = &http.Client{Timeout: o.Cfg.WebhookTimeout}
+		backoff = 500 * time.Millisecond
+		err     error
+	)
+
+	if client.Timeout <= 0 {
+		client.Timeout = defaultWebhookTimeout
+	}
+
+	for attempt := 0; attempt <= o.Cfg.WebhookRetries; attempt++ {
+		if attempt > 0 {
+			var timer = time.NewTimer(backoff)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return errors.Join(ctx.Err(), err)
+			}
+			backoff *= 2
+		}
+
+		var retry bool
+		retry, err = o.doWebhook(ctx, client, url, body, sign)
+		if err == nil || !retry {
+			return err
+		}
+	}
+
+	return err
+}
+
+// doWebhook sends one request and reports whether failure is worth retrying
+func (o *ObjectFunction) doWebhook(ctx context.Context, client *http.Client, url string, body []byte, sign bool) (bool, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	for key, value := range o.Cfg.WebhookHeaders {
+		request.Header.Set(key, value)
+	}
+
+	if sign && o.Cfg.WebhookSecret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		request.Header.Set(WebhookTimestampHeader, timestamp)
+		request.Header.Set(WebhookSignatureHeader, "sha256="+HmacSHA256(timestamp+"."+string(body), o.Cfg.WebhookSecret))
+	}
+
+	resp, err := client.Do(request)
+	if err != nil {
+		// cancelled request is not retried
+		return ctx.Err() == nil, fmt.Errorf("error sending webhook to %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		respByte, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, fmt.Errorf("webhook %s responded %d: %s", url, resp.StatusCode, string(respByte))
+	}
+
+	return false, nil
+}